@@ -0,0 +1,170 @@
+package goreq
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pathPlaceholder matches a {name} placeholder of a path parameter.
+var pathPlaceholder = regexp.MustCompile(`\{[A-Za-z0-9_.]+\}`)
+
+// SetBaseURL sets a base url which relative urls of Get, Post and the other methods are resolved against.
+// The base url is treated as a directory, so relative paths are appended to it
+// while paths starting with "/" replace its path, as described in RFC 3986.
+//
+// For example:
+//
+//      gr := goreq.New().SetBaseURL("http://example.com/api/v1")
+//      gr.Get("users").End()            // http://example.com/api/v1/users
+//      gr.Get("/status").End()          // http://example.com/status
+//      gr.Get("http://other.com").End() // http://other.com
+//
+// Reset keeps the base url.
+func (gr *GoReq) SetBaseURL(baseURL string) *GoReq {
+	gr.baseURL = baseURL
+	return gr
+}
+
+// PathParam sets the value of a {name} placeholder in the url. The value is escaped as a path segment.
+//
+// For example:
+//
+//      goreq.New().
+//        Get("http://example.com/users/{id}/repos/{repo}").
+//        PathParam("id", "42").
+//        PathParam("repo", "go req").
+//        End()
+//
+// requests http://example.com/users/42/repos/go%20req.
+// End returns an error if a placeholder in the path is not set. The url is only expanded as a template once a path parameter is set,
+// so braces in the query such as ?filter={} are kept otherwise.
+func (gr *GoReq) PathParam(name string, value string) *GoReq {
+	return gr.TemplateVar(name, value)
+}
+
+// PathParams sets values of {name} placeholders in the url. See PathParam.
+func (gr *GoReq) PathParams(params map[string]string) *GoReq {
+	for k, v := range params {
+		gr.PathParam(k, v)
+	}
+	return gr
+}
+
+// TemplateVar sets a variable of the url, which is expanded as a URI template (RFC 6570) up to level 4.
+// The value can be a string, a list or an associative array, see URITemplate.Expand.
+//
+// For example:
+//
+//      goreq.New().
+//        Get("http://example.com/repos{/owner,repo}/issues{?state,labels}").
+//        TemplateVar("owner", "smallnest").
+//        TemplateVar("repo", "goreq").
+//        TemplateVar("labels", []string{"bug", "help wanted"}).
+//        End()
+//
+// expands to http://example.com/repos/smallnest/goreq/issues?labels=bug,help%20wanted.
+// Variables in expressions with an operator such as {?state} are optional,
+// but End returns an error if a simple expression such as {id} has no value.
+// Only urls with variables are expanded, and the urls of Follow and Paginate are never templates.
+func (gr *GoReq) TemplateVar(name string, value interface{}) *GoReq {
+	if gr.templateVars == nil {
+		gr.templateVars = make(map[string]interface{})
+	}
+	gr.templateVars[name] = value
+	return gr
+}
+
+// TemplateVars sets variables of the url. See TemplateVar.
+func (gr *GoReq) TemplateVars(vars map[string]interface{}) *GoReq {
+	for k, v := range vars {
+		gr.TemplateVar(k, v)
+	}
+	return gr
+}
+
+// requestURL returns the url to request, resolved against the base url.
+// The urls are only expanded as URI templates if a path parameter or template variable is set,
+// so that other urls with braces such as ?filter={} are sent as they are, but {name} placeholders in their paths are errors.
+func (gr *GoReq) requestURL() (string, error) {
+	target, base := gr.URL, gr.baseURL
+	if len(gr.templateVars) > 0 {
+		var err error
+		if target, err = expandURL(target, gr.templateVars); err != nil {
+			return "", err
+		}
+		if base, err = expandURL(base, gr.templateVars); err != nil {
+			return "", err
+		}
+	} else {
+		for _, u := range []string{target, base} {
+			if err := checkPlaceholders(u); err != nil {
+				return "", err
+			}
+		}
+	}
+	if base == "" {
+		return target, nil
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+		if baseURL.RawPath != "" {
+			baseURL.RawPath += "/"
+		}
+	}
+	return baseURL.ResolveReference(targetURL).String(), nil
+}
+
+// checkPlaceholders returns an error if the path of rawURL has {name} placeholders.
+func checkPlaceholders(rawURL string) error {
+	path := rawURL
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if missing := pathPlaceholder.FindAllString(path, -1); len(missing) > 0 {
+		return fmt.Errorf("path parameters %s are not set in %s", strings.Join(missing, ", "), rawURL)
+	}
+	return nil
+}
+
+// expandURL expands rawURL as a URI template.
+// Unlike RFC 6570, it returns an error if no variable of a simple expression such as {id} is set.
+func expandURL(rawURL string, vars map[string]interface{}) (string, error) {
+	if !strings.ContainsAny(rawURL, "{}") {
+		return rawURL, nil
+	}
+	t, err := ParseURITemplate(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for _, part := range t.parts {
+		if part.op != simpleOperator {
+			continue
+		}
+		set := false
+		for _, varspec := range part.varspecs {
+			if v, _ := templateValue(vars[varspec.name]); v != nil {
+				set = true
+			}
+		}
+		if !set {
+			missing = append(missing, "{"+part.varspecs[0].name+"}")
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("path parameters %s are not set in %s", strings.Join(missing, ", "), rawURL)
+	}
+	return t.Expand(vars)
+}
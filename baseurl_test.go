@@ -0,0 +1,82 @@
+package goreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBaseURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.EscapedPath())
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		base, target, path string
+	}{
+		{ts.URL + "/api/v1", "users", "/api/v1/users"},
+		{ts.URL + "/api/v1/", "users/42", "/api/v1/users/42"},
+		{ts.URL + "/api/v1", "/status", "/status"},
+		{ts.URL, "users", "/users"},
+		{"http://unused.example.com/api", ts.URL + "/absolute", "/absolute"},
+	}
+	for _, c := range cases {
+		_, body, errs := New().SetBaseURL(c.base).Get(c.target).End()
+		if errs != nil {
+			t.Fatalf("Unexpected errors: %s", errs)
+		}
+		if body != c.path {
+			t.Errorf("Expected %s + %s to request %s | but got %s", c.base, c.target, c.path, body)
+		}
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.EscapedPath())
+	}))
+	defer ts.Close()
+
+	_, body, errs := New().SetBaseURL(ts.URL+"/{version}").
+		Get("users/{id}/repos/{repo}").
+		PathParam("id", "42").
+		PathParams(map[string]string{"repo": "go req/x", "version": "v1"}).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if body != "/v1/users/42/repos/go%20req%2Fx" {
+		t.Errorf("Expected escaped path /v1/users/42/repos/go%%20req%%2Fx | but got %s", body)
+	}
+
+	_, _, errs = New().Get(ts.URL + "/users/{id}/repos/{repo}").PathParam("id", "42").End()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "{repo}") {
+		t.Errorf("Expected an error for unfilled {repo} | but got %v", errs)
+	}
+
+	// without any path parameter
+	_, _, errs = New().SetBaseURL(ts.URL+"/{version}").Get("users/{id}?filter={}").End()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "{id}") {
+		t.Errorf("Expected an error for unfilled {id} | but got %v", errs)
+	}
+}
+
+func TestURLWithBraces(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Query().Get("filter"))
+	}))
+	defer ts.Close()
+
+	for _, filter := range []string{`{}`, `{"a":1}`} {
+		_, body, errs := New().Get(ts.URL + "/?filter=" + filter).End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+		if body != filter {
+			t.Errorf("Expected filter %s | but got %s", filter, body)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package goreq
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Clone returns a deep copy of the GoReq, so the copy can be changed and sent without affecting the original.
+// Headers, query, url variables, data, cookies, errors, retry config and transport settings are copied.
+// The client, logger, redirect policy and the BindBody target are shared.
+// The copy shares the transport (and its connections) with the original until either of them changes a transport setting.
+//
+// Clone must not be called concurrently on the same GoReq unless it is a template, see Template.
+func (gr *GoReq) Clone() *GoReq {
+	clone := *gr
+	clone.Header = make(map[string]string, len(gr.Header))
+	for k, v := range gr.Header {
+		clone.Header[k] = v
+	}
+	clone.Data = copyMap(gr.Data)
+	clone.FormData = copyValues(gr.FormData)
+	clone.QueryData = copyValues(gr.QueryData)
+	clone.templateVars = copyMap(gr.templateVars)
+	if gr.RawBytesData != nil {
+		clone.RawBytesData = append([]byte(nil), gr.RawBytesData...)
+	}
+	clone.Cookies = make([]*http.Cookie, len(gr.Cookies))
+	for i, c := range gr.Cookies {
+		cookie := *c
+		clone.Cookies[i] = &cookie
+	}
+	if gr.Errors != nil {
+		clone.Errors = append([]error(nil), gr.Errors...)
+	}
+	if gr.retry != nil {
+		retry := *gr.retry
+		if retry.RetryOnHTTPStatus != nil {
+			retry.RetryOnHTTPStatus = append([]int(nil), retry.RetryOnHTTPStatus...)
+		}
+		clone.retry = &retry
+	}
+	clone.template = false
+
+	// share the transport copy-on-write
+	if !gr.sharedTransport {
+		gr.sharedTransport = true
+	}
+	clone.sharedTransport = true
+	return &clone
+}
+
+// Template makes the GoReq a template for concurrent requests.
+// Get, Post, Head, Put, Delete, Patch and Options of a template don't change it
+// but return a Clone with the method and url set, so a preconfigured GoReq can be kept and used by many goroutines.
+// Configure a template before using it concurrently.
+//
+// For example:
+//    api := goreq.New().
+//        SetHeader("API-Key", "fookey").
+//        Timeout(5 * time.Second).
+//        Retry(3, 1, []int{503}).
+//        Template()
+//
+//    go api.Get("http://example.com/users").End()
+//    go api.Get("http://example.com/groups").End()
+//
+func (gr *GoReq) Template() *GoReq {
+	// clones share the client and its cookies
+	if gr.Client == nil {
+		gr.setDefaultClient()
+	}
+	gr.template = true
+	gr.sharedTransport = true
+	return gr
+}
+
+// spawn returns a clone of a template or the GoReq itself.
+func (gr *GoReq) spawn() *GoReq {
+	if gr.template {
+		return gr.Clone()
+	}
+	return gr
+}
+
+func copyValues(values url.Values) url.Values {
+	if values == nil {
+		return nil
+	}
+	c := make(url.Values, len(values))
+	for k, v := range values {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = copyValue(v)
+	}
+	return c
+}
+
+// copyValue copies the maps and slices made by SendStruct and SendMapString.
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return copyMap(val)
+	case []interface{}:
+		c := make([]interface{}, len(val))
+		for i, e := range val {
+			c[i] = copyValue(e)
+		}
+		return c
+	case []string:
+		return append([]string(nil), val...)
+	}
+	return v
+}
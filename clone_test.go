@@ -0,0 +1,103 @@
+package goreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	gr := New().Post("http://example.com").
+		SetHeader("API-Key", "fookey").
+		Query("query1=test1").
+		SendMapString(`{"name":"nemo","tags":["a","b"]}`).
+		AddCookie(&http.Cookie{Name: "session", Value: "1"}).
+		Retry(3, 1, []int{503})
+
+	clone := gr.Clone()
+	clone.SetHeader("API-Key", "barkey").
+		Param("query1", "test2").
+		SendMapString(`{"name":"dory"}`).
+		Retry(1, 0, nil)
+	clone.Data["tags"].([]interface{})[0] = "c"
+	clone.Cookies[0].Value = "2"
+
+	if gr.Header["API-Key"] != "fookey" {
+		t.Errorf("Expected original header fookey | but got %s", gr.Header["API-Key"])
+	}
+	if len(gr.QueryData["query1"]) != 1 {
+		t.Errorf("Expected original query unchanged | but got %v", gr.QueryData)
+	}
+	if gr.Data["name"] != "nemo" || gr.Data["tags"].([]interface{})[0] != "a" {
+		t.Errorf("Expected original data unchanged | but got %v", gr.Data)
+	}
+	if gr.Cookies[0].Value != "1" {
+		t.Errorf("Expected original cookie unchanged | but got %s", gr.Cookies[0].Value)
+	}
+	if gr.retry.RetryCount != 3 || gr.retry.RetryOnHTTPStatus[0] != 503 {
+		t.Errorf("Expected original retry config unchanged | but got %v", gr.retry)
+	}
+	if clone.Method != POST || clone.URL != "http://example.com" {
+		t.Errorf("Expected cloned method and url | but got %s %s", clone.Method, clone.URL)
+	}
+
+	clone.Proxy("http://127.0.0.1:9999")
+	if gr.Transport.Proxy != nil {
+		t.Error("Expected original transport unchanged")
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("API-Key") != "fookey" {
+			t.Errorf("Expected 'API-Key' == %q; got %q", "fookey", r.Header.Get("API-Key"))
+		}
+		fmt.Fprint(w, r.URL.Query().Get("id"))
+	}))
+	defer ts.Close()
+
+	api := New().SetHeader("API-Key", "fookey").Template()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprint(i)
+			_, body, errs := api.Get(ts.URL).Param("id", id).End()
+			if errs != nil || body != id {
+				t.Errorf("Expected body %s | but got %q, errors: %v", id, body, errs)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if api.URL != "" || api.Method != "" || len(api.QueryData) != 0 {
+		t.Errorf("Expected template unchanged | but got %s %s %v", api.Method, api.URL, api.QueryData)
+	}
+}
+
+func TestTemplateTimeout(t *testing.T) {
+	ts, conns := newConnCountingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello world")
+	}))
+
+	api := New().SetHeader("API-Key", "fookey").Template()
+	for i := 0; i < 5; i++ {
+		gr := api.Get(ts.URL).Timeout(time.Second)
+		_, body, errs := gr.End()
+		if errs != nil || body != "hello world" {
+			t.Fatalf("Unexpected response %q, errors: %v", body, errs)
+		}
+		if gr.Transport != api.Transport {
+			t.Fatal("Expected a per-call timeout to keep the transport of the template")
+		}
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("Expected 1 shared connection | but got %d", n)
+	}
+}
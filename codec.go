@@ -0,0 +1,166 @@
+package goreq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Codec encodes request bodies and decodes response bodies of some media types.
+// The codec of a request body is selected by the Content-Type of the request, and the codec used by BindBody
+// by the Content-Type of the response.
+type Codec interface {
+	// MediaTypes returns the media types handled by the codec, such as "application/json".
+	MediaTypes() []string
+	// Encode writes the encoding of v to w.
+	Encode(w io.Writer, v interface{}) error
+	// Decode reads an encoded value from r and stores it in v.
+	Decode(r io.Reader, v interface{}) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = make(map[string]Codec)
+)
+
+func init() {
+	RegisterCodec(jsonCodec{})
+	RegisterCodec(formCodec{})
+}
+
+// RegisterCodec registers a codec for its media types, replacing the codecs registered before for them.
+// Media types with a structured syntax suffix, such as "application/vnd.foo+json", use the codec of
+// "application/json" unless a codec is registered for them.
+//
+// For example:
+//    goreq.RegisterCodec(myCodec)
+//    goreq.ShortContentTypes["my"] = "application/x-my-type"
+//
+//    goreq.New().
+//      Post("/update").
+//      ContentType("my").
+//      SendStruct(data).
+//      End()
+//
+func RegisterCodec(codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	for _, mediaType := range codec.MediaTypes() {
+		codecs[strings.ToLower(mediaType)] = codec
+	}
+}
+
+// LookupCodec returns the codec of a Content-Type, or nil if there is none.
+func LookupCodec(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	if codec, ok := codecs[mediaType]; ok {
+		return codec
+	}
+	// structured syntax suffix, see RFC 6839
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		return codecs["application/"+mediaType[i+1:]]
+	}
+	return nil
+}
+
+// jsonCodec encodes and decodes application/json.
+type jsonCodec struct{}
+
+func (jsonCodec) MediaTypes() []string {
+	return []string{"application/json", "text/json"}
+}
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// formCodec encodes and decodes application/x-www-form-urlencoded.
+// Values are encoded by `form` tags like SendForm, and decoded into a *url.Values, *map[string][]string or *map[string]string.
+type formCodec struct{}
+
+func (formCodec) MediaTypes() []string {
+	return []string{"application/x-www-form-urlencoded"}
+}
+
+func (formCodec) Encode(w io.Writer, v interface{}) error {
+	values, err := encodeValues(v, "form")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+
+func (formCodec) Decode(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+	switch target := v.(type) {
+	case *url.Values:
+		*target = values
+	case *map[string][]string:
+		*target = values
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for k := range values {
+			m[k] = values.Get(k)
+		}
+		*target = m
+	default:
+		return fmt.Errorf("goreq: can not decode form into %T", v)
+	}
+	return nil
+}
+
+// plainNumbers returns v with the json.Number values of SendStruct and SendMapString data
+// converted into int64 or float64, so that codecs which don't encode JSON get plain numbers.
+func plainNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			m[k] = plainNumbers(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, e := range val {
+			s[i] = plainNumbers(e)
+		}
+		return s
+	}
+	return v
+}
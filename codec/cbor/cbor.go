@@ -0,0 +1,42 @@
+// Package cbor registers a CBOR (RFC 8949) codec for goreq. Import it for its side effect:
+//
+//    import _ "github.com/smallnest/goreq/codec/cbor"
+//
+// Then the data of SendStruct and SendMapString is encoded in CBOR with the "cbor" content type,
+// and BindBody decodes application/cbor responses.
+package cbor
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/smallnest/goreq"
+)
+
+func init() {
+	goreq.RegisterCodec(Codec{})
+}
+
+// decMode decodes maps into map[string]interface{} like encoding/json.
+var decMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+}.DecMode()
+
+// Codec encodes and decodes CBOR. Struct fields use `cbor` tags, falling back to `json` tags.
+type Codec struct{}
+
+// MediaTypes returns application/cbor.
+func (Codec) MediaTypes() []string {
+	return []string{"application/cbor"}
+}
+
+// Encode writes the CBOR encoding of v to w.
+func (Codec) Encode(w io.Writer, v interface{}) error {
+	return cbor.NewEncoder(w).Encode(v)
+}
+
+// Decode decodes CBOR from r into v.
+func (Codec) Decode(r io.Reader, v interface{}) error {
+	return decMode.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,73 @@
+package cbor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/smallnest/goreq"
+)
+
+type telemetry struct {
+	Host    string             `json:"host"`
+	Count   int                `json:"count"`
+	Load    float64            `json:"load"`
+	Tags    []string           `json:"tags"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+var telemetrySample = telemetry{
+	Host:    "web-1",
+	Count:   3,
+	Load:    0.5,
+	Tags:    []string{"a", "b"},
+	Metrics: map[string]float64{"cpu": 0.25},
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/cbor" {
+			t.Errorf("Expected Header Content-Type -> application/cbor | but got %s", r.Header.Get("Content-Type"))
+		}
+		var received telemetry
+		if err := cbor.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(received, telemetrySample) {
+			t.Errorf("Expected %+v | but got %+v", telemetrySample, received)
+		}
+		w.Header().Set("Content-Type", "application/cbor")
+		cbor.NewEncoder(w).Encode(received)
+	}))
+	defer ts.Close()
+
+	var reply telemetry
+	_, _, errs := goreq.New().Post(ts.URL).
+		ContentType("cbor").
+		SendStruct(telemetrySample).
+		BindBody(&reply).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(reply, telemetrySample) {
+		t.Errorf("Expected BindBody to decode %+v | but got %+v", telemetrySample, reply)
+	}
+
+	// maps are decoded with string keys
+	var generic interface{}
+	_, _, errs = goreq.New().Post(ts.URL).
+		ContentType("cbor").
+		SendStruct(telemetrySample).
+		BindBody(&generic).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if m, ok := generic.(map[string]interface{}); !ok || m["host"] != "web-1" {
+		t.Errorf("Expected a map[string]interface{} | but got %#v", generic)
+	}
+}
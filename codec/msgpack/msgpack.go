@@ -0,0 +1,40 @@
+// Package msgpack registers a MessagePack codec for goreq. Import it for its side effect:
+//
+//    import _ "github.com/smallnest/goreq/codec/msgpack"
+//
+// Then the data of SendStruct and SendMapString is encoded in MessagePack with the "msgpack" content type,
+// and BindBody decodes application/msgpack responses.
+package msgpack
+
+import (
+	"io"
+
+	"github.com/smallnest/goreq"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func init() {
+	goreq.RegisterCodec(Codec{})
+}
+
+// Codec encodes and decodes MessagePack. Struct fields use `msgpack` tags, falling back to `json` tags.
+type Codec struct{}
+
+// MediaTypes returns the media types of MessagePack.
+func (Codec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+// Encode writes the MessagePack encoding of v to w.
+func (Codec) Encode(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+// Decode decodes MessagePack from r into v.
+func (Codec) Decode(r io.Reader, v interface{}) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
@@ -0,0 +1,78 @@
+package msgpack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/smallnest/goreq"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type telemetry struct {
+	Host    string             `json:"host"`
+	Count   int                `json:"count"`
+	Load    float64            `json:"load"`
+	Tags    []string           `json:"tags"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+var telemetrySample = telemetry{
+	Host:    "web-1",
+	Count:   3,
+	Load:    0.5,
+	Tags:    []string{"a", "b"},
+	Metrics: map[string]float64{"cpu": 0.25},
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/msgpack" {
+			t.Errorf("Expected Header Content-Type -> application/msgpack | but got %s", r.Header.Get("Content-Type"))
+		}
+		var received telemetry
+		dec := msgpack.NewDecoder(r.Body)
+		dec.SetCustomStructTag("json")
+		if err := dec.Decode(&received); err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(received, telemetrySample) {
+			t.Errorf("Expected %+v | but got %+v", telemetrySample, received)
+		}
+		w.Header().Set("Content-Type", "application/x-msgpack")
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		enc.Encode(received)
+	}))
+	defer ts.Close()
+
+	var reply telemetry
+	_, _, errs := goreq.New().Post(ts.URL).
+		ContentType("msgpack").
+		SendStruct(telemetrySample).
+		BindBody(&reply).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(reply, telemetrySample) {
+		t.Errorf("Expected BindBody to decode %+v | but got %+v", telemetrySample, reply)
+	}
+
+	// data of SendMapString
+	reply = telemetry{}
+	_, _, errs = goreq.New().Post(ts.URL).
+		ContentType("msgpack").
+		SendMapString(`{"host":"web-1","count":3,"load":0.5,"tags":["a","b"]}`).
+		SendMapString(`{"metrics":{"cpu":0.25}}`).
+		BindBody(&reply).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(reply, telemetrySample) {
+		t.Errorf("Expected BindBody to decode %+v | but got %+v", telemetrySample, reply)
+	}
+}
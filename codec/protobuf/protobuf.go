@@ -0,0 +1,110 @@
+// Package protobuf registers codecs of Protocol Buffers for goreq. Import it for its side effect:
+//
+//    import _ "github.com/smallnest/goreq/codec/protobuf"
+//
+// Then SendProto and the data of BindBody are encoded and decoded in the protobuf wire format for application/x-protobuf,
+// and proto.Message values of JSON bodies in the JSON mapping of protobuf, as gRPC gateways do.
+package protobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/smallnest/goreq"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func init() {
+	goreq.RegisterCodec(&Codec{})
+	goreq.RegisterCodec(&JSONCodec{Unmarshal: protojson.UnmarshalOptions{DiscardUnknown: true}})
+}
+
+// Codec encodes and decodes proto.Message values in the protobuf wire format.
+// It is registered for application/x-protobuf, application/protobuf and application/vnd.google.protobuf.
+type Codec struct {
+	Marshal   proto.MarshalOptions
+	Unmarshal proto.UnmarshalOptions
+}
+
+// MediaTypes returns the media types of protobuf.
+func (c *Codec) MediaTypes() []string {
+	return []string{"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"}
+}
+
+// Encode writes the wire format of v, which must be a proto.Message, to w.
+func (c *Codec) Encode(w io.Writer, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("goreq: can not encode %T as protobuf", v)
+	}
+	b, err := c.Marshal.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Decode decodes the wire format from r into v, which must be a proto.Message.
+func (c *Codec) Decode(r io.Reader, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("goreq: can not decode protobuf into %T", v)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal.Unmarshal(b, m)
+}
+
+// JSONCodec encodes and decodes proto.Message values in the JSON mapping of protobuf, and other values with encoding/json.
+// It is registered for application/json with unknown fields discarded. Register another JSONCodec to change the options:
+//
+//    goreq.RegisterCodec(&protobuf.JSONCodec{
+//        Marshal: protojson.MarshalOptions{UseProtoNames: true},
+//    })
+//
+type JSONCodec struct {
+	Marshal   protojson.MarshalOptions
+	Unmarshal protojson.UnmarshalOptions
+}
+
+// MediaTypes returns application/json.
+func (c *JSONCodec) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+// Encode writes the JSON encoding of v to w.
+func (c *JSONCodec) Encode(w io.Writer, v interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+	if m, ok := v.(proto.Message); ok {
+		b, err = c.Marshal.Marshal(m)
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Decode decodes JSON from r into v.
+func (c *JSONCodec) Decode(r io.Reader, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return json.NewDecoder(r).Decode(v)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal.Unmarshal(b, m)
+}
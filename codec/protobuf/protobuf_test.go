@@ -0,0 +1,97 @@
+package protobuf
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/smallnest/goreq"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSendProto(t *testing.T) {
+	msg, err := structpb.NewStruct(map[string]interface{}{"name": "nemo", "age": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var received structpb.Struct
+		switch r.Header.Get("Content-Type") {
+		case "application/x-protobuf":
+			err = proto.Unmarshal(body, &received)
+		case "application/json":
+			err = protojson.Unmarshal(body, &received)
+		default:
+			t.Errorf("Unexpected Content-Type %s", r.Header.Get("Content-Type"))
+			return
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !proto.Equal(&received, msg) {
+			t.Errorf("Expected %v | but got %v", msg, &received)
+		}
+		// echo the message in the requested format
+		w.Header().Set("Content-Type", r.Header.Get("Accept"))
+		if r.Header.Get("Accept") == "application/x-protobuf" {
+			b, _ := proto.Marshal(&received)
+			w.Write(b)
+		} else {
+			b, _ := protojson.Marshal(&received)
+			w.Write(b)
+		}
+	}))
+	defer ts.Close()
+
+	for _, c := range []struct{ contentType, accept string }{
+		{"", "application/x-protobuf"},
+		{"json", "application/json"},
+		{"protobuf", "application/json"},
+	} {
+		var reply structpb.Struct
+		_, _, errs := goreq.New().Post(ts.URL).
+			ContentType(c.contentType).
+			SetHeader("Accept", c.accept).
+			SendProto(msg).
+			BindBody(&reply).
+			End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+			continue
+		}
+		if !proto.Equal(&reply, msg) {
+			t.Errorf("Expected BindBody to decode %v from %s | but got %v", msg, c.accept, &reply)
+		}
+	}
+}
+
+func TestBindBodyProtoJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`"2018-07-27T10:30:00Z"`))
+	}))
+	defer ts.Close()
+
+	var reply timestamppb.Timestamp
+	_, _, errs := goreq.New().Get(ts.URL).BindBody(&reply).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if expected := time.Date(2018, 7, 27, 10, 30, 0, 0, time.UTC); !reply.AsTime().Equal(expected) {
+		t.Errorf("Expected %s | but got %s", expected, reply.AsTime())
+	}
+}
+
+func TestCodecNotMessage(t *testing.T) {
+	if _, _, errs := goreq.New().Post("http://localhost").ContentType("protobuf").SendStruct(struct{ A int }{1}).End(); errs == nil {
+		t.Error("Expected an error for SendStruct with a protobuf content type")
+	}
+}
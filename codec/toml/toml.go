@@ -0,0 +1,36 @@
+// Package toml registers a TOML codec for goreq. Import it for its side effect:
+//
+//    import _ "github.com/smallnest/goreq/codec/toml"
+//
+// Then SendTOML and the data of SendStruct and SendMapString with the "toml" content type are encoded in TOML,
+// and BindBody decodes application/toml responses.
+package toml
+
+import (
+	"io"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/smallnest/goreq"
+)
+
+func init() {
+	goreq.RegisterCodec(Codec{})
+}
+
+// Codec encodes and decodes TOML with `toml` tags.
+type Codec struct{}
+
+// MediaTypes returns the media type of TOML.
+func (Codec) MediaTypes() []string {
+	return []string{"application/toml"}
+}
+
+// Encode writes the TOML encoding of v, which must be a struct or a map, to w.
+func (Codec) Encode(w io.Writer, v interface{}) error {
+	return toml.NewEncoder(w).Encode(v)
+}
+
+// Decode decodes TOML from r into v.
+func (Codec) Decode(r io.Reader, v interface{}) error {
+	return toml.NewDecoder(r).Decode(v)
+}
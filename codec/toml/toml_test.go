@@ -0,0 +1,71 @@
+package toml
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/smallnest/goreq"
+)
+
+type deployConfig struct {
+	Name     string            `toml:"name"`
+	Replicas int               `toml:"replicas"`
+	Ports    []int             `toml:"ports"`
+	Labels   map[string]string `toml:"labels"`
+}
+
+var deployConfigSample = deployConfig{
+	Name:     "web",
+	Replicas: 3,
+	Ports:    []int{80, 443},
+	Labels:   map[string]string{"tier": "frontend"},
+}
+
+func TestSendTOML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/toml" {
+			t.Errorf("Expected Header Content-Type -> application/toml | but got %s", r.Header.Get("Content-Type"))
+		}
+		expected := "name = 'web'\nreplicas = 3\nports = [80, 443]\n\n[labels]\ntier = 'frontend'\n"
+		if string(body) != expected {
+			t.Errorf("Expected Body %q | but got %q", expected, body)
+		}
+		w.Header().Set("Content-Type", "application/toml")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	var reply deployConfig
+	_, _, errs := goreq.New().Put(ts.URL).SendTOML(deployConfigSample).BindBody(&reply).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(reply, deployConfigSample) {
+		t.Errorf("Expected BindBody to decode %+v | but got %+v", deployConfigSample, reply)
+	}
+}
+
+func TestTOMLData(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "name = 'web'\nreplicas = 3\n" {
+			t.Errorf("Expected Body with toml data | but got %q", body)
+		}
+		w.Header().Set("Content-Type", "application/toml")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	var data map[string]interface{}
+	_, _, errs := goreq.New().Post(ts.URL).ContentType("toml").SendMapString(`{"name":"web","replicas":3}`).BindBody(&data).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if data["name"] != "web" || data["replicas"] != int64(3) {
+		t.Errorf("Expected toml data | but got %v", data)
+	}
+}
@@ -0,0 +1,40 @@
+// Package yaml registers a YAML codec for goreq. Import it for its side effect:
+//
+//    import _ "github.com/smallnest/goreq/codec/yaml"
+//
+// Then SendYAML and the data of SendStruct and SendMapString with the "yaml" content type are encoded in YAML,
+// and BindBody decodes application/yaml and text/yaml responses.
+package yaml
+
+import (
+	"io"
+
+	"github.com/smallnest/goreq"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	goreq.RegisterCodec(Codec{})
+}
+
+// Codec encodes and decodes YAML with `yaml` tags.
+type Codec struct{}
+
+// MediaTypes returns the media types of YAML.
+func (Codec) MediaTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}
+}
+
+// Encode writes the YAML encoding of v to w.
+func (Codec) Encode(w io.Writer, v interface{}) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Decode decodes YAML from r into v.
+func (Codec) Decode(r io.Reader, v interface{}) error {
+	return yaml.NewDecoder(r).Decode(v)
+}
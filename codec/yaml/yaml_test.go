@@ -0,0 +1,65 @@
+package yaml
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/smallnest/goreq"
+)
+
+type deployConfig struct {
+	Name     string            `yaml:"name"`
+	Replicas int               `yaml:"replicas"`
+	Ports    []int             `yaml:"ports"`
+	Labels   map[string]string `yaml:"labels"`
+}
+
+var deployConfigSample = deployConfig{
+	Name:     "web",
+	Replicas: 3,
+	Ports:    []int{80, 443},
+	Labels:   map[string]string{"tier": "frontend"},
+}
+
+func TestSendYAML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/yaml" {
+			t.Errorf("Expected Header Content-Type -> application/yaml | but got %s", r.Header.Get("Content-Type"))
+		}
+		expected := "name: web\nreplicas: 3\nports:\n    - 80\n    - 443\nlabels:\n    tier: frontend\n"
+		if string(body) != expected {
+			t.Errorf("Expected Body %q | but got %q", expected, body)
+		}
+		w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	var reply deployConfig
+	_, _, errs := goreq.New().Put(ts.URL).SendYAML(deployConfigSample).BindBody(&reply).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(reply, deployConfigSample) {
+		t.Errorf("Expected BindBody to decode %+v | but got %+v", deployConfigSample, reply)
+	}
+}
+
+func TestYAMLData(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "name: web\nreplicas: 3\n" {
+			t.Errorf("Expected Body with yaml data | but got %q", body)
+		}
+	}))
+	defer ts.Close()
+
+	_, _, errs := goreq.New().Post(ts.URL).ContentType("yaml").SendMapString(`{"name":"web","replicas":3}`).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+}
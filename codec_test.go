@@ -0,0 +1,170 @@
+package goreq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// lineCodec encodes a map[string]interface{} as sorted "key: value" lines.
+type lineCodec struct{}
+
+func (lineCodec) MediaTypes() []string {
+	return []string{"text/x-goreq-lines"}
+}
+
+func (lineCodec) Encode(w io.Writer, v interface{}) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("can not encode %T", v)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s: %v\n", k, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (lineCodec) Decode(r io.Reader, v interface{}) error {
+	m, ok := v.(*map[string]string)
+	if !ok {
+		return fmt.Errorf("can not decode into %T", v)
+	}
+	*m = make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), ": ", 2)
+		if len(kv) == 2 {
+			(*m)[kv[0]] = kv[1]
+		}
+	}
+	return scanner.Err()
+}
+
+func TestLookupCodec(t *testing.T) {
+	cases := []struct {
+		contentType string
+		expected    Codec
+	}{
+		{"application/json", jsonCodec{}},
+		{"Application/JSON; charset=utf-8", jsonCodec{}},
+		{"application/vnd.github.v3+json", jsonCodec{}},
+		{"application/x-www-form-urlencoded", formCodec{}},
+		{"text/plain", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		if codec := LookupCodec(c.contentType); codec != c.expected {
+			t.Errorf("Expected codec %T for %q | but got %T", c.expected, c.contentType, codec)
+		}
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec(lineCodec{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "age: 3\nname: nemo\n" {
+			t.Errorf("Expected Body encoded by the codec | but got %q", body)
+		}
+		w.Header().Set("Content-Type", "text/x-goreq-lines; charset=utf-8")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	var result map[string]string
+	_, _, errs := New().Post(ts.URL).
+		ContentType("text/x-goreq-lines").
+		SendMapString(`{"name":"nemo","age":3}`).
+		BindBody(&result).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	expected := map[string]string{"name": "nemo", "age": "3"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected BindBody to decode %v | but got %v", expected, result)
+	}
+}
+
+func TestCodecBySuffix(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"name":"nemo"}` {
+			t.Errorf("Expected json Body | but got %s", body)
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	_, _, errs := New().Post(ts.URL).
+		ContentType("application/vnd.foo+json; version=2").
+		SendMapString(`{"name":"nemo"}`).
+		BindBody(&result).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if result.Name != "nemo" {
+		t.Errorf("Expected BindBody to decode nemo | but got %s", result.Name)
+	}
+}
+
+func TestBindBodyForm(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=abc&scope=a&scope=b"))
+	}))
+	defer ts.Close()
+
+	var result url.Values
+	_, _, errs := New().Get(ts.URL).BindBody(&result).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	expected := url.Values{"access_token": {"abc"}, "scope": {"a", "b"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected BindBody to decode %v | but got %v", expected, result)
+	}
+}
+
+func TestSendWithoutCodec(t *testing.T) {
+	_, _, errs := New().Post("http://localhost").SendProto(struct{}{}).End()
+	if len(errs) != 1 || errs[0].Error() != "goreq: no codec is registered for Content-Type application/x-protobuf" {
+		t.Errorf("Expected an error for a Content-Type without codec | but got %v", errs)
+	}
+}
+
+func TestFormCodecNil(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if len(body) != 0 {
+			t.Errorf("Expected an empty form | but got %s", body)
+		}
+	}))
+	defer ts.Close()
+
+	// null is a valid JSON value, which is an empty form
+	if _, _, errs := New().Post(ts.URL).ContentType("form").SendJSON(nil).End(); errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+}
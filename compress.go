@@ -0,0 +1,182 @@
+package goreq
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms supported by CompressBody and response decompression.
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+	Zstd    = "zstd"
+	Brotli  = "br"
+)
+
+// acceptEncoding is sent in Accept-Encoding if decompression is enabled.
+const acceptEncoding = "gzip, deflate, br, zstd"
+
+// CompressBody compresses the request body with the given algorithm and sets the Content-Encoding header.
+// It works with every kind of body EndBytes builds: json, form, raw string, raw bytes and multipart files.
+// The body is compressed again for every attempt so Retry resends a complete body.
+//
+// For example:
+//
+//      goreq.New().
+//        Post("/documents").
+//        SendStruct(doc).
+//        CompressBody(goreq.Gzip).
+//        End()
+//
+// Supported algorithms are "gzip", "deflate" and "zstd". An empty string disables compression.
+func (gr *GoReq) CompressBody(algorithm string) *GoReq {
+	switch algorithm {
+	case "", Gzip, Deflate, Zstd:
+		gr.compression = algorithm
+	default:
+		gr.Errors = append(gr.Errors, fmt.Errorf("unsupported compression algorithm %q", algorithm))
+	}
+	return gr
+}
+
+// compressBody compresses data with the algorithm.
+func compressBody(algorithm string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := newCompressWriter(algorithm, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newCompressWriter returns a writer which compresses data with the algorithm into w.
+func newCompressWriter(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	switch algorithm {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Deflate:
+		// HTTP "deflate" is the zlib format (RFC 7230 section 4.2.2)
+		return zlib.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+}
+
+// SetDecompress enables or disables automatic decompression of responses. It is enabled by default.
+// When enabled, GoReq sends "Accept-Encoding: gzip, deflate, br, zstd" unless you set Accept-Encoding yourself,
+// and decodes the response body according to its Content-Encoding.
+// When disabled, GoReq neither advertises nor decodes encodings, and neither does its transport,
+// so you get the raw bytes as sent by the server, encoded if you set Accept-Encoding:
+//
+//      goreq.New().
+//        Get("http://example.com/archive").
+//        SetDecompress(false).
+//        SetHeader("Accept-Encoding", "br").
+//        EndBytes()
+func (gr *GoReq) SetDecompress(enable bool) *GoReq {
+	gr.decompress = enable
+	return gr
+}
+
+// decompressResponse replaces the body of resp with a decoded one according to its Content-Encoding.
+// The body is left as it is if it uses an encoding GoReq does not know.
+func decompressResponse(resp *http.Response) error {
+	var encodings []string
+	for _, e := range strings.Split(resp.Header.Get("Content-Encoding"), ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		switch e {
+		case "", "identity":
+		case Gzip, "x-gzip", Deflate, Brotli, Zstd:
+			encodings = append(encodings, e)
+		default:
+			return nil
+		}
+	}
+	if len(encodings) == 0 {
+		return nil
+	}
+
+	// responses to HEAD requests and the like have no body to decode
+	br := bufio.NewReader(resp.Body)
+	if _, err := br.Peek(1); err == io.EOF {
+		return nil
+	}
+
+	var body io.ReadCloser = multiCloser{ioutil.NopCloser(br), resp.Body}
+	// codings are listed in the order in which they were applied
+	for i := len(encodings) - 1; i >= 0; i-- {
+		r, err := newDecompressReader(encodings[i], body)
+		if err != nil {
+			return err
+		}
+		body = multiCloser{r, body}
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// newDecompressReader returns a reader which decodes r with the encoding.
+func newDecompressReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case Gzip, "x-gzip":
+		return gzip.NewReader(r)
+	case Deflate:
+		// some servers send raw deflate data instead of the zlib format
+		br := bufio.NewReader(r)
+		header, err := br.Peek(2)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	case Brotli:
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	case Zstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// multiCloser reads from a decoder and closes both the decoder and the underlying reader.
+type multiCloser struct {
+	io.ReadCloser
+	underlying io.Closer
+}
+
+func (c multiCloser) Close() error {
+	err := c.ReadCloser.Close()
+	if err2 := c.underlying.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
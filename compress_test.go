@@ -0,0 +1,253 @@
+package goreq
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func decompressRequestBody(t *testing.T, r *http.Request) string {
+	var (
+		reader io.Reader
+		err    error
+	)
+	switch r.Header.Get("Content-Encoding") {
+	case Gzip:
+		reader, err = gzip.NewReader(r.Body)
+	case Deflate:
+		reader, err = zlib.NewReader(r.Body)
+	case Zstd:
+		var d *zstd.Decoder
+		d, err = zstd.NewReader(r.Body)
+		if err == nil {
+			defer d.Close()
+		}
+		reader = d
+	default:
+		t.Errorf("Unexpected Content-Encoding %q", r.Header.Get("Content-Encoding"))
+		return ""
+	}
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Error(err)
+	}
+	return string(body)
+}
+
+func TestCompressBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := decompressRequestBody(t, r)
+		switch r.Header.Get("Content-Type") {
+		case "application/json":
+			if body != `{"name":"nemo"}` {
+				t.Errorf(`Expected Body with {"name":"nemo"} | but got %s`, body)
+			}
+		case "application/x-www-form-urlencoded":
+			if body != "name=nemo" {
+				t.Errorf("Expected Body with name=nemo | but got %s", body)
+			}
+		case "application/octet-stream":
+			if body != "hello world" {
+				t.Errorf("Expected Body with hello world | but got %s", body)
+			}
+		default:
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			form, err := multipart.NewReader(strings.NewReader(body), params["boundary"]).ReadForm(1 << 20)
+			if err != nil {
+				t.Errorf("Expected a multipart body | but got %v", err)
+				return
+			}
+			if form.Value["name"][0] != "nemo" || len(form.File["test"]) != 1 || form.File["test"][0].Filename != "LICENSE" {
+				t.Errorf("Expected field name=nemo and file LICENSE | but got %v %v", form.Value, form.File)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	for _, algorithm := range []string{Gzip, Deflate, Zstd} {
+		_, _, errs := New().Post(ts.URL).
+			SendMapString(`{"name":"nemo"}`).
+			CompressBody(algorithm).
+			End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+
+		_, _, errs = New().Post(ts.URL).
+			ContentType("form").
+			SendMapString("name=nemo").
+			CompressBody(algorithm).
+			End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+
+		_, _, errs = New().Post(ts.URL).
+			SendRawBytes([]byte("hello world")).
+			CompressBody(algorithm).
+			End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+
+		_, _, errs = New().Post(ts.URL).
+			SendMapString("name=nemo").
+			SendFile("test", "./LICENSE").
+			CompressBody(algorithm).
+			End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+	}
+
+	_, _, errs := New().Post(ts.URL).CompressBody("lzma").End()
+	if len(errs) != 1 {
+		t.Errorf("Expected an error for unsupported algorithm | but got %v", errs)
+	}
+}
+
+func TestCompressBodyRetry(t *testing.T) {
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if body := decompressRequestBody(t, r); body != "hello world" {
+			t.Errorf("Expected Body with hello world in attempt %d | but got %s", count, body)
+		}
+		if count < 3 {
+			w.WriteHeader(503)
+		}
+	}))
+	defer ts.Close()
+
+	resp, _, errs := New().Post(ts.URL).
+		SendRawString("hello world").
+		CompressBody(Gzip).
+		Retry(3, 0, []int{503}).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if resp.StatusCode != 200 || count != 3 {
+		t.Errorf("Expected 200 after 3 attempts | but got %d after %d", resp.StatusCode, count)
+	}
+}
+
+func compressResponseBody(t *testing.T, encoding string, data []byte) []byte {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+		err error
+	)
+	switch encoding {
+	case Brotli:
+		w = brotli.NewWriter(&buf)
+	case "raw-deflate":
+		w, err = flate.NewWriter(&buf, flate.DefaultCompression)
+	default:
+		return compressBodyOrFail(t, encoding, data)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func compressBodyOrFail(t *testing.T, encoding string, data []byte) []byte {
+	b, err := compressBody(encoding, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDecompressResponse(t *testing.T) {
+	serverOutput := "hello world"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.URL.Query().Get("encoding")
+		if r.URL.Query().Get("expected_accept") != r.Header.Get("Accept-Encoding") {
+			t.Errorf("Expected Accept-Encoding %q | but got %q", r.URL.Query().Get("expected_accept"), r.Header.Get("Accept-Encoding"))
+		}
+		if encoding == "raw-deflate" {
+			w.Header().Set("Content-Encoding", Deflate)
+		} else {
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		if r.Method == HEAD {
+			return
+		}
+		w.Write(compressResponseBody(t, encoding, []byte(serverOutput)))
+	}))
+	defer ts.Close()
+
+	for _, encoding := range []string{Gzip, Deflate, "raw-deflate", Brotli, Zstd} {
+		resp, body, errs := New().Get(ts.URL).
+			Param("encoding", encoding).
+			Param("expected_accept", acceptEncoding).
+			End()
+		if errs != nil {
+			t.Fatalf("Unexpected errors for %s: %s", encoding, errs)
+		}
+		if body != serverOutput {
+			t.Errorf("Expected body %q for %s | but got %q", serverOutput, encoding, body)
+		}
+		if resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("Expected Content-Encoding to be removed for %s", encoding)
+		}
+	}
+
+	_, _, errs := New().Head(ts.URL).
+		Param("encoding", Gzip).
+		Param("expected_accept", acceptEncoding).
+		End()
+	if errs != nil {
+		t.Errorf("Unexpected errors for HEAD: %s", errs)
+	}
+
+	// raw bytes when decompression is disabled
+	resp, body, errs := New().Get(ts.URL).
+		SetDecompress(false).
+		SetHeader("Accept-Encoding", Brotli).
+		Param("encoding", Brotli).
+		Param("expected_accept", Brotli).
+		EndBytes()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !bytes.Equal(body, compressResponseBody(t, Brotli, []byte(serverOutput))) {
+		t.Errorf("Expected raw brotli bytes | but got %q", body)
+	}
+	if resp.Header.Get("Content-Encoding") != Brotli {
+		t.Errorf("Expected Content-Encoding %q | but got %q", Brotli, resp.Header.Get("Content-Encoding"))
+	}
+
+	// the transport neither asks for gzip nor decodes it
+	resp, body, errs = New().Get(ts.URL).
+		SetDecompress(false).
+		Param("encoding", Gzip).
+		Param("expected_accept", "").
+		EndBytes()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !bytes.Equal(body, compressResponseBody(t, Gzip, []byte(serverOutput))) || resp.Uncompressed {
+		t.Errorf("Expected raw gzip bytes | but got %q", body)
+	}
+}
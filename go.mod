@@ -0,0 +1,23 @@
+module github.com/smallnest/goreq
+
+go 1.24.0
+
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/elazarl/goproxy v1.9.2
+	github.com/fxamacker/cbor/v2 v2.9.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/klauspost/compress v1.18.0
+	github.com/moul/http2curl v1.0.0
+	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/net v0.50.0
+	google.golang.org/protobuf v1.36.11
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/text v0.34.0 // indirect
+)
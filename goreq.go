@@ -21,9 +21,12 @@ package goreq
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"mime/multipart"
@@ -84,6 +87,21 @@ type GoReq struct {
 	logger           *log.Logger
 	retry            *RetryConfig
 	bindResponseBody interface{}
+	compression      string
+	decompress       bool
+	maxResponseBytes int64
+	maxDecompressed  int64
+	connectTimeout   time.Duration
+	timeout          time.Duration
+	deadline         time.Duration
+	sharedTransport  bool
+	template         bool
+	baseURL          string
+	templateVars     map[string]interface{}
+	bodyValue        *bodyValue
+	structValue      interface{}
+	prettyXML        bool
+	ctx              context.Context
 }
 
 // RetryConfig is used to config retry parameters
@@ -104,7 +122,7 @@ func New() *GoReq {
 		FormData:         url.Values{},
 		QueryData:        url.Values{},
 		Client:           nil,
-		Transport:        &http.Transport{},
+		Transport:        newTransport(),
 		Cookies:          make([]*http.Cookie, 0),
 		Errors:           nil,
 		BasicAuth:        struct{ Username, Password string }{},
@@ -113,6 +131,7 @@ func New() *GoReq {
 		logger:           log.New(os.Stderr, "[goreq]", log.LstdFlags),
 		retry:            &RetryConfig{RetryCount: 0, RetryTimeout: 0, RetryOnHTTPStatus: nil},
 		bindResponseBody: nil,
+		decompress:       true,
 	}
 	return gr
 }
@@ -135,7 +154,9 @@ func (gr *GoReq) SetLogger(logger *log.Logger) *GoReq {
 	return gr
 }
 
-// SetClient ise used to set a shared http.Client
+// SetClient ise used to set a shared http.Client.
+// The client is never modified: transport settings and redirect policy of this GoReq only apply to its own requests,
+// so many GoReq instances can share a client concurrently.
 func (gr *GoReq) SetClient(client *http.Client) *GoReq {
 	gr.Client = client
 	return gr
@@ -167,12 +188,18 @@ func (gr *GoReq) Reset() *GoReq {
 	gr.Errors = nil
 	gr.retry = &RetryConfig{RetryCount: 0, RetryTimeout: 0, RetryOnHTTPStatus: nil}
 	gr.bindResponseBody = nil
+	gr.compression = ""
+	gr.templateVars = nil
+	gr.bodyValue = nil
+	gr.structValue = nil
+	gr.ctx = nil
 	return gr
 }
 
 // Get is used to set GET HttpMethod with a url.
 func (gr *GoReq) Get(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = GET
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -182,6 +209,7 @@ func (gr *GoReq) Get(targetURL string) *GoReq {
 // Post is used to set POST HttpMethod with a url.
 func (gr *GoReq) Post(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = POST
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -191,6 +219,7 @@ func (gr *GoReq) Post(targetURL string) *GoReq {
 // Head is used to set HEAD HttpMethod with a url.
 func (gr *GoReq) Head(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = HEAD
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -200,6 +229,7 @@ func (gr *GoReq) Head(targetURL string) *GoReq {
 // Put is used to set PUT HttpMethod with a url.
 func (gr *GoReq) Put(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = PUT
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -209,6 +239,7 @@ func (gr *GoReq) Put(targetURL string) *GoReq {
 // Delete is used to set DELETE HttpMethod with a url.
 func (gr *GoReq) Delete(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = DELETE
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -218,6 +249,7 @@ func (gr *GoReq) Delete(targetURL string) *GoReq {
 // Patch is used to set PATCH HttpMethod with a url.
 func (gr *GoReq) Patch(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = PATCH
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -227,6 +259,7 @@ func (gr *GoReq) Patch(targetURL string) *GoReq {
 // Options is used to set OPTIONS HttpMethod with a url.
 func (gr *GoReq) Options(targetURL string) *GoReq {
 	//gr.Reset()
+	gr = gr.spawn()
 	gr.Method = OPTIONS
 	gr.URL = targetURL
 	gr.Errors = nil
@@ -334,6 +367,11 @@ var ShortContentTypes = map[string]string{
 	"form":       "application/x-www-form-urlencoded",
 	"form-data":  "application/x-www-form-urlencoded",
 	"stream":     "application/octet-stream",
+	"protobuf":   "application/x-protobuf",
+	"msgpack":    "application/msgpack",
+	"cbor":       "application/cbor",
+	"yaml":       "application/yaml",
+	"toml":       "application/toml",
 }
 
 // ContentType is a convenience function to specify the data type to send instead of SetHeader("Content-Type", "......").
@@ -354,6 +392,14 @@ var ShortContentTypes = map[string]string{
 //    "xml" as "application/xml"
 //    "urlencoded", "form" or "form-data" as "application/x-www-form-urlencoded"
 //    "stream" as "application/octet-stream"
+//    "protobuf" as "application/x-protobuf"
+//    "msgpack" as "application/msgpack"
+//    "cbor" as "application/cbor"
+//    "yaml" as "application/yaml"
+//    "toml" as "application/toml"
+//
+// Data of SendStruct and SendMapString is encoded by the codec of the content type, see RegisterCodec.
+// The codecs of protobuf, msgpack, cbor, yaml and toml are registered by importing their packages in github.com/smallnest/goreq/codec.
 //
 func (gr *GoReq) ContentType(typeStr string) *GoReq {
 	if ShortContentTypes[typeStr] != "" {
@@ -396,12 +442,29 @@ func (gr *GoReq) ContentType(typeStr string) *GoReq {
 //        Query(`{ "size": "50x50", "weight":"20kg" }`).
 //        End()
 //
+// Structs (or pointers to structs) are encoded by `url` tags, falling back to `json` tags:
+//
+//      type Search struct {
+//        Query  string    `url:"query"`
+//        Page   int       `url:"page,omitempty"`
+//        Since  time.Time `url:"since" layout:"2006-01-02"`
+//        Tags   []string  `url:"tags,comma"`
+//        Filter Filter    `url:"filter"`
+//      }
+//
+// Tag options are omitempty, comma or brackets for slices (slices are repeated by default),
+// dot for nested structs (brackets by default: filter[state]=open), int for bools, and unix or unixmilli for time.Time.
+// Nil pointers are omitted.
 func (gr *GoReq) Query(content interface{}) *GoReq {
 	switch v := reflect.ValueOf(content); v.Kind() {
 	case reflect.String:
 		gr.queryString(v.String())
 	case reflect.Struct:
 		gr.queryStruct(v.Interface())
+	case reflect.Ptr:
+		if v.Elem().Kind() == reflect.Struct {
+			gr.queryStruct(v.Interface())
+		}
 	default:
 	}
 	return gr
@@ -412,17 +475,14 @@ func (gr *GoReq) BindHost(host string) *GoReq {
 	return gr
 }
 
-//create queryData by parsing structs.
+//create queryData by parsing structs with `url` tags.
 func (gr *GoReq) queryStruct(content interface{}) *GoReq {
-	if marshalContent, err := json.Marshal(content); err != nil {
+	if values, err := encodeValues(content, "url"); err != nil {
 		gr.Errors = append(gr.Errors, err)
 	} else {
-		var val map[string]interface{}
-		if err := json.Unmarshal(marshalContent, &val); err != nil {
-			gr.Errors = append(gr.Errors, err)
-		} else {
-			for k, v := range val {
-				gr.QueryData.Add(k, v.(string))
+		for k, v := range values {
+			for _, vv := range v {
+				gr.QueryData.Add(k, vv)
 			}
 		}
 	}
@@ -470,24 +530,111 @@ func (gr *GoReq) Socks5(network, addr string, auth *proxy.Auth, forward proxy.Di
 	dialer, err := proxy.SOCKS5(network, addr, auth, forward)
 	if err != nil {
 		gr.Errors = append(gr.Errors, err)
+	} else if d, ok := dialer.(proxy.ContextDialer); ok {
+		gr.ownTransport().DialContext = dialContext(d.DialContext)
 	} else {
-		gr.Transport.Dial = dialer.Dial
+		gr.ownTransport().DialContext = dialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.Dial(network, addr)
+		})
 	}
 	return gr
 }
 
-// Timeout is used to set timeout for connections.
+// Timeout is used to set timeout for each attempt of a request.
+// It limits connecting, sending the request and reading the whole response,
+// but unlike a deadline on the connection it keeps the connection reusable for keep-alive.
+// Use Deadline to limit the total time including retries.
+// Like ConnectTimeout and Deadline, it applies to the requests of this GoReq only and keeps sharing the transport of a Pool.
 func (gr *GoReq) Timeout(timeout time.Duration) *GoReq {
-	gr.Transport.Dial = func(network, addr string) (net.Conn, error) {
-		conn, err := net.DialTimeout(network, addr, timeout)
-		if err != nil {
-			gr.Errors = append(gr.Errors, err)
-			return nil, err
+	gr.timeout = timeout
+	return gr
+}
+
+// ConnectTimeout is used to set timeout for establishing connections, including connecting to a SOCKS5 proxy.
+// It is passed to the transport with each request, so it needs a transport of GoReq, made by New or NewPool.
+func (gr *GoReq) ConnectTimeout(timeout time.Duration) *GoReq {
+	gr.connectTimeout = timeout
+	return gr
+}
+
+// TLSHandshakeTimeout is used to set timeout for TLS handshakes.
+func (gr *GoReq) TLSHandshakeTimeout(timeout time.Duration) *GoReq {
+	gr.ownTransport().TLSHandshakeTimeout = timeout
+	return gr
+}
+
+// ResponseHeaderTimeout is used to set timeout for waiting the response headers after the request is written.
+func (gr *GoReq) ResponseHeaderTimeout(timeout time.Duration) *GoReq {
+	gr.ownTransport().ResponseHeaderTimeout = timeout
+	return gr
+}
+
+// IdleConnTimeout is used to set how long an idle keep-alive connection stays in the pool.
+func (gr *GoReq) IdleConnTimeout(timeout time.Duration) *GoReq {
+	gr.ownTransport().IdleConnTimeout = timeout
+	return gr
+}
+
+// Deadline is used to limit the total time of End and EndBytes, including all retries, the waits between them and reading the response.
+//
+// For example:
+//    _, _, errs := New().Get("http://example.com").
+//    Timeout(time.Second).
+//    Retry(3, 1, []int{503}).
+//    Deadline(5 * time.Second).
+//    End()
+//
+func (gr *GoReq) Deadline(timeout time.Duration) *GoReq {
+	gr.deadline = timeout
+	return gr
+}
+
+// WithContext sets the context of the request. Canceling ctx aborts the request, the waits between retries and reading the response.
+func (gr *GoReq) WithContext(ctx context.Context) *GoReq {
+	gr.ctx = ctx
+	return gr
+}
+
+// context returns the context of the request.
+func (gr *GoReq) context() context.Context {
+	if gr.ctx != nil {
+		return gr.ctx
+	}
+	return context.Background()
+}
+
+// connectTimeoutKey is the context key of the ConnectTimeout of a request.
+type connectTimeoutKey struct{}
+
+// newTransport returns a transport which dials within the ConnectTimeout of each request.
+// It doesn't ask for gzip itself since GoReq decodes the responses, see SetDecompress.
+func newTransport() *http.Transport {
+	return &http.Transport{DialContext: dialContext(nil), DisableCompression: true}
+}
+
+// dialContext returns a DialContext for a transport which dials with dial (or directly)
+// within the ConnectTimeout in the context of the request.
+func dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if timeout, _ := ctx.Value(connectTimeoutKey{}).(time.Duration); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
 		}
-		conn.SetDeadline(time.Now().Add(timeout))
-		return conn, nil
+		return dial(ctx, network, addr)
 	}
-	return gr
+}
+
+// requestContext returns the context of the request with its ConnectTimeout.
+func (gr *GoReq) requestContext() context.Context {
+	ctx := gr.context()
+	if gr.connectTimeout > 0 {
+		ctx = context.WithValue(ctx, connectTimeoutKey{}, gr.connectTimeout)
+	}
+	return ctx
 }
 
 // TLSClientConfig is used to set TLSClientConfig for underling Transport.
@@ -498,7 +645,7 @@ func (gr *GoReq) Timeout(timeout time.Duration) *GoReq {
 //        End()
 //
 func (gr *GoReq) TLSClientConfig(config *tls.Config) *GoReq {
-	gr.Transport.TLSClientConfig = config
+	gr.ownTransport().TLSClientConfig = config
 	return gr
 }
 
@@ -523,9 +670,9 @@ func (gr *GoReq) Proxy(proxyURL string) *GoReq {
 	if err != nil {
 		gr.Errors = append(gr.Errors, err)
 	} else if proxyURL == "" {
-		gr.Transport.Proxy = nil
+		gr.ownTransport().Proxy = nil
 	} else {
-		gr.Transport.Proxy = http.ProxyURL(parsedProxyURL)
+		gr.ownTransport().Proxy = http.ProxyURL(parsedProxyURL)
 	}
 	return gr
 }
@@ -539,9 +686,6 @@ func (gr *GoReq) RedirectPolicy(policy func(req Request, via []Request) error) *
 		}
 		return policy(Request(r), vv)
 	}
-	if gr.Client != nil {
-		gr.Client.CheckRedirect = gr.CheckRedirect
-	}
 	return gr
 }
 
@@ -558,7 +702,10 @@ func (gr *GoReq) RedirectPolicy(policy func(req Request, via []Request) error) *
 //        SendStruct(ver).
 //        SendStruct(`{"Safari":"5.1.10"}`).
 //        End()
+//
+// Codecs other than JSON and form, such as XML, encode the struct itself if it is the only data of SendStruct and SendMapString.
 func (gr *GoReq) SendStruct(content interface{}) *GoReq {
+	first := len(gr.Data) == 0
 	if marshalContent, err := json.Marshal(content); err != nil {
 		gr.Errors = append(gr.Errors, err)
 	} else {
@@ -571,11 +718,102 @@ func (gr *GoReq) SendStruct(content interface{}) *GoReq {
 			for k, v := range val {
 				gr.Data[k] = v
 			}
+			// codecs other than JSON and form encode the struct itself unless more data is merged into it
+			gr.structValue = nil
+			if first {
+				gr.structValue = content
+			}
 		}
 	}
 	return gr
 }
 
+// SendJSON sends v as the JSON body. Unlike SendStruct, v is marshaled as it is when the request is sent,
+// so arrays, scalars, the order of struct fields and custom MarshalJSON methods are kept:
+//      goreq.New().
+//        Post("/users").
+//        SendJSON([]User{{Name: "Jerry"}, {Name: "Tom"}}).
+//        End()
+//
+// SendJSON replaces the value of a previous SendJSON, SendXML, SendProto, SendYAML or SendTOML, and can not be combined with SendStruct or SendMapString,
+// which merge their contents into a JSON object.
+// The value is encoded by the codec of the Content-Type when the request is sent, so another Content-Type set by ContentType
+// encodes it in that media type.
+func (gr *GoReq) SendJSON(v interface{}) *GoReq {
+	gr.bodyValue = &bodyValue{value: v, codec: jsonCodec{}}
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/json"
+	}
+	return gr
+}
+
+// SendJSONStream is like SendJSON, but a slice, an array or a channel is encoded as a JSON array one element at a time
+// while the request is sent, so only one element is held encoded in memory. A channel is received until it is closed:
+//      items := make(chan Item)
+//      go func() {
+//        defer close(items)
+//        for rows.Next() {
+//          items <- scan(rows)
+//        }
+//      }()
+//      goreq.New().
+//        Post("/import").
+//        SendJSONStream(items).
+//        End()
+//
+// Other values are encoded whole. The body is sent with chunked transfer encoding and is encoded again for every retry,
+// but a channel can only be sent once, so a retry of its body fails.
+func (gr *GoReq) SendJSONStream(v interface{}) *GoReq {
+	gr.SendJSON(v)
+	gr.bodyValue.stream = true
+	return gr
+}
+
+// bodyValue is a value to send as the request body, encoded by codec.
+type bodyValue struct {
+	value  interface{}
+	codec  Codec
+	stream bool
+}
+
+// SendForm encodes a struct (or a pointer to a struct, or a map) into form fields by `form` tags, falling back to `json` tags.
+// The fields are sent as application/x-www-form-urlencoded, or as multipart fields together with SendFile.
+// Nested structs and maps are encoded in bracket notation, and slices are repeated:
+//      type Address struct {
+//        City string `form:"city"`
+//      }
+//      type Profile struct {
+//        Name    string    `form:"name"`
+//        Age     int       `form:"age"`
+//        Admin   bool      `form:"admin"`
+//        Tags    []string  `form:"tags"`
+//        Address Address   `form:"address"`
+//        Friends []Address `form:"friends"`
+//      }
+//      goreq.New().
+//        Post("/profile").
+//        SendForm(profile).
+//        End()
+//
+// sends name=x&age=3&admin=true&tags=a&tags=b&address[city]=y&friends[0][city]=z.
+// Tag options are the same as the `url` tags of Query.
+func (gr *GoReq) SendForm(content interface{}) *GoReq {
+	values, err := encodeValues(content, "form")
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return gr
+	}
+	for k, v := range values {
+		for _, vv := range v {
+			gr.FormData.Add(k, vv)
+		}
+	}
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/x-www-form-urlencoded"
+	}
+	return gr
+}
+
 // SendMapString returns *GoReq's itself for any next chain and takes content string as a parameter.
 // Its duty is to transform json String or query Strings into s.Data (map[string]interface{}) which later changes into appropriate format such as json, form, text, etc. in the End func.
 // SendMapString function accepts either json string or other strings which is usually used to assign data to POST or PUT method.
@@ -608,7 +846,9 @@ func (gr *GoReq) SendMapString(content string) *GoReq {
 		for k, v := range val {
 			gr.Data[k] = v
 		}
+		gr.structValue = nil
 	} else if formVal, err2 := url.ParseQuery(content); err2 == nil {
+		gr.structValue = nil
 		for k := range formVal {
 			// make it array if already have key
 			if val, ok := gr.Data[k]; ok {
@@ -666,7 +906,7 @@ func (gr *GoReq) SendFile(paramName, filePath string) *GoReq {
 }
 
 //copy from https://matt.aimonetti.net/posts/2013/07/01/golang-multipart-file-upload-example/
-func newfileUploadRequest(gr *GoReq, params map[string]string, paramName, filePath string) (*bytes.Buffer, error) {
+func newfileUploadRequest(gr *GoReq, params url.Values, paramName, filePath string) (*bytes.Buffer, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -689,8 +929,10 @@ func newfileUploadRequest(gr *GoReq, params map[string]string, paramName, filePa
 	}
 	part.Write(fileContents)
 
-	for key, val := range params {
-		_ = writer.WriteField(key, val)
+	for key, vals := range params {
+		for _, val := range vals {
+			_ = writer.WriteField(key, val)
+		}
 	}
 	err = writer.Close()
 	if err != nil {
@@ -701,48 +943,21 @@ func newfileUploadRequest(gr *GoReq, params map[string]string, paramName, filePa
 	return body, nil
 }
 
-func changeMapToURLValues(data map[string]interface{}) url.Values {
-	var newURLValues = url.Values{}
-	for k, v := range data {
-		switch val := v.(type) {
-		case string:
-			newURLValues.Add(k, val)
-		case []string:
-			for _, element := range val {
-				newURLValues.Add(k, element)
-			}
-		// if a number, change to string
-		// json.Number used to protect against a wrong (for GoReq) default conversion
-		// which always converts number to float64.
-		// This type is caused by using Decoder.UseNumber()
-		case json.Number:
-			newURLValues.Add(k, string(val))
-		}
+// formValues returns the form fields of gr.Data and gr.FormData.
+// Values of gr.Data are encoded the same way as SendForm, so numbers, bools and nested objects are kept.
+func (gr *GoReq) formValues() (url.Values, error) {
+	values, err := encodeValues(gr.Data, "form")
+	if err != nil {
+		return nil, err
 	}
-	return newURLValues
-}
-
-func changeMapToMapString(data map[string]interface{}) map[string]string {
-	var m map[string]string
-
-	for k, v := range data {
-		switch val := v.(type) {
-		case string:
-			m[k] = val
-		case []string:
-			m[k] = strings.Join(val, ",")
-		// if a number, change to string
-		// json.Number used to protect against a wrong (for GoReq) default conversion
-		// which always converts number to float64.
-		// This type is caused by using Decoder.UseNumber()
-		case json.Number:
-			m[k] = string(val)
-		}
+	for k, v := range gr.FormData {
+		values[k] = append(values[k], v...)
 	}
-	return m
+	return values, nil
 }
 
 // BindBody set bind object for response.
+// The response body is decoded by the codec of its Content-Type (see RegisterCodec), or as JSON if there is none.
 //
 // For example:
 //    type Person struct {
@@ -789,8 +1004,12 @@ func (gr *GoReq) End(callback ...func(response Response, body string, errs []err
 		}
 	}
 	resp, body, errs := gr.EndBytes(bytesCallback...)
-	if gr.bindResponseBody != nil {
-		json.Unmarshal(body, gr.bindResponseBody)
+	if gr.bindResponseBody != nil && resp != nil {
+		codec := LookupCodec(resp.Header.Get("Content-Type"))
+		if codec == nil {
+			codec = jsonCodec{}
+		}
+		codec.Decode(bytes.NewReader(body), gr.bindResponseBody)
 	}
 	bodyString := string(body)
 	return resp, bodyString, errs
@@ -798,6 +1017,30 @@ func (gr *GoReq) End(callback ...func(response Response, body string, errs []err
 
 // EndBytes should be used when you want the body as bytes. The callbacks work the same way as with `End`, except that a byte array is used instead of a string.
 func (gr *GoReq) EndBytes(callback ...func(response Response, body []byte, errs []error)) (Response, []byte, []error) {
+	resp, errs := gr.send()
+	if errs != nil {
+		return nil, nil, errs
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, nil, gr.Errors
+	}
+	// Reset resp.Body so it can be use again
+	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	// deep copy response to give it to both return and callback func
+	respCallback := *resp
+	if len(callback) != 0 {
+		callback[0](&respCallback, body, gr.Errors)
+	}
+	return resp, body, nil
+}
+
+// send sends the request and returns the response with its body not read yet, or all errors.
+// The body is decompressed and limited by MaxResponseBytes and MaxDecompressedBytes, and must be closed by the caller.
+func (gr *GoReq) send() (*http.Response, []error) {
 	var (
 		req  *http.Request
 		err  error
@@ -805,7 +1048,13 @@ func (gr *GoReq) EndBytes(callback ...func(response Response, body []byte, errs
 	)
 	// check whether there is an error. if yes, return all errors
 	if len(gr.Errors) != 0 {
-		return nil, nil, gr.Errors
+		return nil, gr.Errors
+	}
+
+	reqURL, err := gr.requestURL()
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, gr.Errors
 	}
 
 	switch gr.Method {
@@ -814,29 +1063,91 @@ func (gr *GoReq) EndBytes(callback ...func(response Response, body []byte, errs
 			gr.Header["Content-Type"] = "application/json"
 		}
 
+		var (
+			reqBody   []byte
+			writeBody func(w io.Writer) error
+			codec     = LookupCodec(gr.Header["Content-Type"])
+		)
 		if gr.FilePath != "" { //post a file
-			buf, _ := newfileUploadRequest(gr, changeMapToMapString(gr.Data), gr.FileParam, gr.FilePath)
-			req, err = http.NewRequest(gr.Method, gr.URL, buf)
-		} else if gr.Header["Content-Type"] == "application/json" && len(gr.Data) > 0 { //json
-			contentJSON, _ := json.Marshal(gr.Data)
-			contentReader := bytes.NewReader(contentJSON)
-			req, err = http.NewRequest(gr.Method, gr.URL, contentReader)
-		} else if gr.Header["Content-Type"] == "application/x-www-form-urlencoded" { //form
-			formData := changeMapToURLValues(gr.Data)
-			req, err = http.NewRequest(gr.Method, gr.URL, strings.NewReader(formData.Encode()))
+			formData, err := gr.formValues()
+			if err != nil {
+				gr.Errors = append(gr.Errors, err)
+				return nil, gr.Errors
+			}
+			buf, err := newfileUploadRequest(gr, formData, gr.FileParam, gr.FilePath)
+			if err != nil {
+				gr.Errors = append(gr.Errors, err)
+				return nil, gr.Errors
+			}
+			reqBody = buf.Bytes()
+		} else if gr.bodyValue != nil { //value
+			if len(gr.Data) > 0 {
+				gr.Errors = append(gr.Errors, errors.New("the value of SendJSON, SendXML, SendProto, SendYAML or SendTOML can not be combined with SendStruct or SendMapString"))
+				return nil, gr.Errors
+			}
+			// the codec of the Content-Type, or the one of SendXML and the like for unknown content types
+			value := gr.bodyValue.value
+			if codec == nil {
+				codec = gr.bodyValue.codec
+			}
+			if codec == nil {
+				gr.Errors = append(gr.Errors, fmt.Errorf("goreq: no codec is registered for Content-Type %s", gr.Header["Content-Type"]))
+				return nil, gr.Errors
+			}
+			if gr.bodyValue.stream && codec == LookupCodec("application/json") {
+				writeBody = jsonStreamWriter(value)
+			} else if reqBody, err = encodeBody(codec, value); err != nil {
+				gr.Errors = append(gr.Errors, err)
+				return nil, gr.Errors
+			}
+		} else if _, ok := codec.(formCodec); ok { //form
+			formData, err := gr.formValues()
+			if err != nil {
+				gr.Errors = append(gr.Errors, err)
+				return nil, gr.Errors
+			}
+			reqBody = []byte(formData.Encode())
+		} else if codec != nil && len(gr.Data) > 0 { //json or a registered codec
+			var data interface{} = gr.Data
+			if codec != LookupCodec("application/json") {
+				data = plainNumbers(gr.Data)
+				if gr.structValue != nil {
+					data = gr.structValue
+				}
+			}
+			if reqBody, err = encodeBody(codec, data); err != nil {
+				gr.Errors = append(gr.Errors, fmt.Errorf("goreq: can not encode the body as %s: %v", gr.Header["Content-Type"], err))
+				return nil, gr.Errors
+			}
 		} else if len(gr.RawBytesData) > 0 { //raw bytes
-			req, err = http.NewRequest(gr.Method, gr.URL, bytes.NewReader(gr.RawBytesData))
+			reqBody = gr.RawBytesData
 		} else { //raw string
-			req, err = http.NewRequest(gr.Method, gr.URL, strings.NewReader(gr.RawStringData))
+			reqBody = []byte(gr.RawStringData)
+		}
+		if writeBody != nil {
+			req, err = gr.newStreamRequest(reqURL, writeBody)
+		} else {
+			req, err = gr.newBodyRequest(reqURL, reqBody)
 		}
 	case GET, HEAD, DELETE, OPTIONS:
-		req, err = http.NewRequest(gr.Method, gr.URL, nil)
+		req, err = http.NewRequest(gr.Method, reqURL, nil)
 
 	default:
 		gr.Errors = append(gr.Errors, errors.New("No method specified"))
-		return nil, nil, gr.Errors
+		return nil, gr.Errors
 	}
-	initRequest(req, gr)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, gr.Errors
+	}
+	req = req.WithContext(gr.requestContext())
+	cancel := func() {}
+	if gr.deadline > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), gr.deadline)
+		req = req.WithContext(ctx)
+	}
+	client := initRequest(req, gr)
 
 	// Log details of this request
 	if gr.Debug {
@@ -845,7 +1156,7 @@ func (gr *GoReq) EndBytes(callback ...func(response Response, body []byte, errs
 		if err != nil {
 			gr.logger.Printf("Error: %s", err.Error())
 		}
-		gr.logger.Printf("HTTP Request: %s", string(dump))
+		gr.logger.Printf("HTTP Request: %s", string(gr.prettyDump(dump, req.Header.Get("Content-Type"))))
 	}
 
 	if gr.CurlCommand {
@@ -859,35 +1170,139 @@ func (gr *GoReq) EndBytes(callback ...func(response Response, body []byte, errs
 	}
 
 	// Send request
-	resp, err = gr.retryDo(req, gr.retry.RetryCount)
+	resp, err = gr.retryDo(client, req, gr.retry.RetryCount)
 
-	// Log details of this response
-	if gr.Debug {
-		dump, err := httputil.DumpResponse(resp, true)
+	// Log details of this response, without the body if its size is limited, because dumping reads it all into memory
+	if gr.Debug && resp != nil {
+		dump, err := httputil.DumpResponse(resp, gr.maxResponseBytes <= 0 && gr.maxDecompressed <= 0)
 		if nil != err {
 			gr.logger.Println("Error: ", err.Error())
 		}
-		gr.logger.Printf("HTTP Response: %s", string(dump))
+		gr.logger.Printf("HTTP Response: %s", string(gr.prettyDump(dump, resp.Header.Get("Content-Type"))))
 	}
 
 	if err != nil {
+		cancel()
 		gr.Errors = append(gr.Errors, err)
-		return nil, nil, gr.Errors
+		return nil, gr.Errors
 	}
-	defer resp.Body.Close()
+	resp.Body = cancelOnClose{resp.Body, cancel}
+	if gr.maxResponseBytes > 0 {
+		if resp.ContentLength > gr.maxResponseBytes {
+			resp.Body.Close()
+			gr.Errors = append(gr.Errors, &ResponseTooLargeError{Limit: gr.maxResponseBytes})
+			return nil, gr.Errors
+		}
+		resp.Body = newLimitReader(resp.Body, gr.maxResponseBytes, false)
+	}
+	if gr.decompress {
+		if err = decompressResponse(resp); err != nil {
+			resp.Body.Close()
+			gr.Errors = append(gr.Errors, err)
+			return nil, gr.Errors
+		}
+		if resp.Uncompressed && gr.maxDecompressed > 0 {
+			resp.Body = newLimitReader(resp.Body, gr.maxDecompressed, true)
+		}
+	}
+	return resp, nil
+}
 
-	body, _ := ioutil.ReadAll(resp.Body)
-	// Reset resp.Body so it can be use again
-	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
-	// deep copy response to give it to both return and callback func
-	respCallback := *resp
-	if len(callback) != 0 {
-		callback[0](&respCallback, body, gr.Errors)
+// encodeBody encodes v with codec.
+func encodeBody(codec Codec, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, v); err != nil {
+		return nil, err
 	}
-	return resp, body, nil
+	return buf.Bytes(), nil
 }
 
-func initRequest(req *http.Request, gr *GoReq) {
+// newBodyRequest creates a request with body, compressing it if CompressBody is set.
+// req.GetBody returns a fresh (and freshly compressed) body so retries can send the request again.
+func (gr *GoReq) newBodyRequest(reqURL string, body []byte) (*http.Request, error) {
+	algorithm := gr.compression
+	if algorithm == "" {
+		return http.NewRequest(gr.Method, reqURL, bytes.NewReader(body))
+	}
+
+	compressed, err := compressBody(algorithm, body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(gr.Method, reqURL, bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		compressed, err := compressBody(algorithm, body)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	req.Header.Set("Content-Encoding", algorithm)
+	return req, nil
+}
+
+// newStreamRequest creates a request whose body is written by write while it is sent, without buffering it.
+// The body is compressed if CompressBody is set. req.GetBody writes the body again for retries.
+func (gr *GoReq) newStreamRequest(reqURL string, write func(w io.Writer) error) (*http.Request, error) {
+	algorithm := gr.compression
+	open := func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			var (
+				w   io.Writer = pw
+				cw  io.WriteCloser
+				err error
+			)
+			if algorithm != "" {
+				if cw, err = newCompressWriter(algorithm, pw); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+				w = cw
+			}
+			err = write(w)
+			if cw != nil {
+				if err2 := cw.Close(); err == nil {
+					err = err2
+				}
+			}
+			pw.CloseWithError(err)
+		}()
+		return pr, nil
+	}
+
+	req, err := http.NewRequest(gr.Method, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Body, _ = open()
+	req.GetBody = open
+	req.ContentLength = -1
+	if algorithm != "" {
+		req.Header.Set("Content-Encoding", algorithm)
+	}
+	return req, nil
+}
+
+// rewindBody prepares the body of req to be sent again.
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// initRequest sets headers, query, auth and cookies of req
+// and returns the client to send it, which is a copy of gr.Client with the settings of gr.
+func initRequest(req *http.Request, gr *GoReq) *http.Client {
 	//bind host
 	req.Host = gr.Host
 	for k, v := range gr.Header {
@@ -902,6 +1317,10 @@ func initRequest(req *http.Request, gr *GoReq) {
 	}
 	req.URL.RawQuery = q.Encode()
 
+	if gr.decompress && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
 	// Add basic auth
 	if gr.BasicAuth != (struct{ Username, Password string }{}) {
 		req.SetBasicAuth(gr.BasicAuth.Username, gr.BasicAuth.Password)
@@ -916,12 +1335,15 @@ func initRequest(req *http.Request, gr *GoReq) {
 	if gr.Client == nil {
 		gr.setDefaultClient()
 	}
+	// copy the client so a shared one is not modified
+	client := *gr.Client
 	if gr.CheckRedirect != nil {
-		gr.Client.CheckRedirect = gr.CheckRedirect
+		client.CheckRedirect = gr.CheckRedirect
 	}
 
 	// Set Transport
-	gr.Client.Transport = gr.Transport
+	client.Transport = gr.Transport
+	return &client
 }
 
 // Retry is used to retry to send requests if servers return unexpected status.
@@ -938,10 +1360,10 @@ func (gr *GoReq) Retry(retryCount int, retryTimeout int, retryOnHTTPStatus []int
 	return gr
 }
 
-func (gr *GoReq) retryDo(req *http.Request, retryCount int) (resp Response, err error) {
-	r, err := gr.Client.Do(req)
+func (gr *GoReq) retryDo(client *http.Client, req *http.Request, retryCount int) (resp Response, err error) {
+	r, err := gr.do(client, req)
 
-	if retryCount == 0 {
+	if retryCount == 0 || err != nil {
 		resp = r
 		return
 	}
@@ -952,14 +1374,27 @@ func (gr *GoReq) retryDo(req *http.Request, retryCount int) (resp Response, err
 			return
 		}
 
-		resp, err = gr.retryDo(req, retryCount-1)
+		r.Body.Close()
+		if err = rewindBody(req); err != nil {
+			return
+		}
+		resp, err = gr.retryDo(client, req, retryCount-1)
 	} else {
 		for _, s := range gr.retry.RetryOnHTTPStatus {
 			if r.StatusCode == s {
+				r.Body.Close()
 				if gr.retry.RetryTimeout > 0 {
-					time.Sleep(time.Duration(gr.retry.RetryTimeout) * time.Second)
+					select {
+					case <-time.After(time.Duration(gr.retry.RetryTimeout) * time.Second):
+					case <-req.Context().Done():
+						err = req.Context().Err()
+						return
+					}
+				}
+				if err = rewindBody(req); err != nil {
+					return
 				}
-				resp, err = gr.retryDo(req, retryCount-1)
+				resp, err = gr.retryDo(client, req, retryCount-1)
 				return
 			}
 		}
@@ -968,3 +1403,30 @@ func (gr *GoReq) retryDo(req *http.Request, retryCount int) (resp Response, err
 	}
 	return
 }
+
+// do sends req once. If Timeout is set the attempt is canceled when it expires or the response body is closed.
+func (gr *GoReq) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if gr.timeout <= 0 {
+		return client.Do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), gr.timeout)
+	r, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	r.Body = cancelOnClose{r.Body, cancel}
+	return r, nil
+}
+
+// cancelOnClose cancels the context of a request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
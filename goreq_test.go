@@ -2,14 +2,18 @@ package goreq
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -173,7 +177,7 @@ func TestPost(t *testing.T) {
 			body, _ := ioutil.ReadAll(r.Body)
 			comparedBody := []byte(`{"Lower":{"Color":"green","Size":1.7},"Upper":{"Color":"red","Size":0},"a":"a","name":"Cindy"}`)
 			if !bytes.Equal(body, comparedBody) {
-				t.Errorf(`Expected correct json but got ` + string(body))
+				t.Error(`Expected correct json but got ` + string(body))
 			}
 		case case8SendJSONWithLongIDNumber:
 			t.Logf("case %v ", case8SendJSONWithLongIDNumber)
@@ -281,6 +285,160 @@ func TestSendFile(t *testing.T) {
 	New().Post(ts.URL).SendFile("test", "./LICENSE").EndBytes()
 }
 
+type jsonTime struct {
+	Sec int64
+}
+
+func (t jsonTime) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"@%d"`, t.Sec)), nil
+}
+
+func TestSendJSON(t *testing.T) {
+	const case1Array = "/array"
+	const case2Scalar = "/scalar"
+	const case3Marshaler = "/marshaler"
+	const case4Stream = "/stream"
+
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected Header Content-Type -> application/json | but got %s", r.Header.Get("Content-Type"))
+		}
+		var body string
+		if r.URL.Path == case4Stream {
+			body = decompressRequestBody(t, r)
+		} else {
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+		}
+		switch r.URL.Path {
+		case case1Array:
+			if body != `[{"name":"Jerry"},{"name":"Tom"}]` {
+				t.Errorf("Expected Body with a json array | but got %s", body)
+			}
+		case case2Scalar:
+			if body != `"hello"` {
+				t.Errorf("Expected Body with a json string | but got %s", body)
+			}
+		case case3Marshaler:
+			if body != `{"z":1,"a":"@10"}` {
+				t.Errorf("Expected Body with fields in order | but got %s", body)
+			}
+		case case4Stream:
+			count++
+			if r.ContentLength != -1 {
+				t.Errorf("Expected a chunked body | but got Content-Length %d", r.ContentLength)
+			}
+			if body != "[1,2,3]" {
+				t.Errorf("Expected Body with [1,2,3] in attempt %d | but got %s", count, body)
+			}
+			if count < 2 {
+				w.WriteHeader(503)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	_, _, errs := New().Post(ts.URL + case1Array).SendJSON([]user{{"Jerry"}, {"Tom"}}).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+	_, _, errs = New().Post(ts.URL + case2Scalar).SendJSON("hello").End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+	_, _, errs = New().Post(ts.URL + case3Marshaler).SendJSON(struct {
+		Z int      `json:"z"`
+		A jsonTime `json:"a"`
+	}{1, jsonTime{10}}).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+
+	resp, _, errs := New().Post(ts.URL+case4Stream).
+		SendJSONStream([]int{1, 2, 3}).
+		CompressBody(Gzip).
+		Retry(2, 0, []int{503}).
+		End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	} else if resp.StatusCode != 200 || count != 2 {
+		t.Errorf("Expected 200 after 2 attempts | but got %d after %d", resp.StatusCode, count)
+	}
+
+	// SendJSON does not merge with SendStruct
+	_, _, errs = New().Post(ts.URL).SendStruct(user{"Jerry"}).SendJSON([]int{1}).End()
+	if errs == nil {
+		t.Error("Expected an error for SendJSON with SendStruct")
+	}
+}
+
+func TestSendJSONStream(t *testing.T) {
+	received := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			t.Errorf("Expected the start of an array | but got %v, %v", tok, err)
+		}
+		var items []map[string]int
+		for dec.More() {
+			var item map[string]int
+			if err := dec.Decode(&item); err != nil {
+				t.Error(err)
+				break
+			}
+			items = append(items, item)
+			if len(items) == 1 {
+				received <- struct{}{}
+			}
+		}
+		dec.Token()
+		if len(items) != 2 || items[1]["id"] != 2 {
+			t.Errorf("Expected 2 items | but got %v", items)
+		}
+		if r.URL.Path == "/retry" {
+			w.WriteHeader(503)
+		}
+	}))
+	defer ts.Close()
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	items := make(chan item)
+	go func() {
+		defer close(items)
+		items <- item{1}
+		// the first item is sent before the channel is closed
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Error("Expected the first item to be received while the body is streamed")
+		}
+		items <- item{2}
+	}()
+	_, _, errs := New().Post(ts.URL).SendJSONStream(items).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+
+	// a channel can not be sent again
+	items = make(chan item, 2)
+	items <- item{1}
+	items <- item{2}
+	close(items)
+	go func() {
+		<-received
+	}()
+	_, _, errs = New().Post(ts.URL+"/retry").SendJSONStream(items).Retry(2, 0, []int{503}).End()
+	if errs == nil {
+		t.Error("Expected an error for a retry of a channel")
+	}
+}
+
 // testing for Patch method
 func TestPatch(t *testing.T) {
 	const case1Empty = "/"
@@ -592,6 +750,92 @@ func TestTimeoutFunc(t *testing.T) {
 
 }
 
+// newConnCountingServer starts a server which counts the connections it accepts. It is closed when the test ends.
+func newConnCountingServer(t *testing.T, handler http.Handler) (*httptest.Server, *int32) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(handler)
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts, &conns
+}
+
+func TestTimeoutKeepAlive(t *testing.T) {
+	ts, conns := newConnCountingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+
+	request := New().Timeout(100 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		_, _, errs := request.Get(ts.URL).End()
+		if errs != nil {
+			t.Fatalf("Unexpected errors: %s", errs)
+		}
+		time.Sleep(150 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("Expected 1 reused connection | but got %d", n)
+	}
+}
+
+func TestFineGrainedTimeouts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+
+	// response header timeout
+	startTime := time.Now()
+	_, _, errs := New().ResponseHeaderTimeout(50 * time.Millisecond).Get(ts.URL).End()
+	if errs == nil {
+		t.Errorf("Expected response header timeout | but get nothing")
+	}
+	if elapsedTime := time.Since(startTime); elapsedTime > 150*time.Millisecond {
+		t.Errorf("Expected timeout in 50ms | but got %v", elapsedTime)
+	}
+
+	// deadline spans retries
+	startTime = time.Now()
+	_, _, errs = New().Get(ts.URL).
+		Timeout(time.Second).
+		Retry(10, 0, []int{503}).
+		Deadline(500 * time.Millisecond).
+		End()
+	if len(errs) == 0 {
+		t.Errorf("Expected deadline error | but get nothing")
+	}
+	if elapsedTime := time.Since(startTime); elapsedTime < 500*time.Millisecond || elapsedTime > 700*time.Millisecond {
+		t.Errorf("Expected deadline in 500ms | but got %v", elapsedTime)
+	}
+}
+
+func TestConnectTimeout(t *testing.T) {
+	var deadline time.Time
+	transport := &http.Transport{DialContext: dialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		deadline, _ = ctx.Deadline()
+		return nil, fmt.Errorf("refused")
+	})}
+
+	gr := New()
+	gr.Transport = transport
+	start := time.Now()
+	_, _, errs := gr.Timeout(5 * time.Second).ConnectTimeout(time.Second).Get("http://example.com").End()
+	if errs == nil {
+		t.Fatal("Expected a dial error")
+	}
+	if gr.Transport != transport {
+		t.Error("Expected the transport not to be replaced by timeouts")
+	}
+	if d := deadline.Sub(start); d < 900*time.Millisecond || d > 1100*time.Millisecond {
+		t.Errorf("Expected a dial deadline in 1s | but got %v", d)
+	}
+}
+
 func TestCookies(t *testing.T) {
 	request := New().Timeout(60 * time.Second)
 	_, _, errs := request.Get("https://github.com").End()
@@ -735,6 +979,70 @@ func TestClient(t *testing.T) {
 		End()
 }
 
+// testing for concurrent GoReqs with different settings sharing a client
+func TestSharedClientConcurrency(t *testing.T) {
+	tsRedirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "redirected")
+	}))
+	defer tsRedirect.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, tsRedirect.URL, http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, r.Header.Get("X-Proxied"))
+	}))
+	defer ts.Close()
+	proxy := goproxy.NewProxyHttpServer()
+	proxy.OnRequest().DoFunc(
+		func(r *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+			r.Header.Set("X-Proxied", "yes")
+			return r, nil
+		})
+	tsProxy := httptest.NewServer(proxy)
+	defer tsProxy.Close()
+
+	client := &http.Client{}
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			switch i % 4 {
+			case 0:
+				_, body, errs := New().SetClient(client).Proxy(tsProxy.URL).Get(ts.URL).End()
+				if errs != nil || body != "yes" {
+					t.Errorf("Expected proxied request | but got %q, errors: %v", body, errs)
+				}
+			case 1:
+				_, body, errs := New().SetClient(client).Timeout(time.Second).Get(ts.URL).End()
+				if errs != nil || body != "" {
+					t.Errorf("Expected direct request | but got %q, errors: %v", body, errs)
+				}
+			case 2:
+				resp, _, errs := New().SetClient(client).
+					RedirectPolicy(func(req Request, via []Request) error {
+						return http.ErrUseLastResponse
+					}).
+					Get(ts.URL + "/redirect").End()
+				if errs != nil || resp.StatusCode != http.StatusFound {
+					t.Errorf("Expected redirect not followed | but got %v, errors: %v", resp, errs)
+				}
+			case 3:
+				_, body, errs := New().SetClient(client).Get(ts.URL + "/redirect").End()
+				if errs != nil || body != "redirected" {
+					t.Errorf("Expected redirect followed | but got %q, errors: %v", body, errs)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if client.Transport != nil || client.CheckRedirect != nil {
+		t.Error("Expected the shared client unchanged")
+	}
+}
+
 func TestRetry(t *testing.T) {
 	count := 4
 
@@ -0,0 +1,190 @@
+package goreq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// graphQLRequest is the body of a GraphQL request, see https://graphql.github.io/graphql-over-http/.
+type graphQLRequest struct {
+	Query      string                 `json:"query,omitempty"`
+	Variables  map[string]interface{} `json:"variables,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse is the response of a GraphQL request.
+type GraphQLResponse struct {
+	Data       json.RawMessage        `json:"data"`
+	Errors     []*GraphQLError        `json:"errors"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// GraphQLError is an error in the response of a GraphQL request.
+type GraphQLError struct {
+	Message string `json:"message"`
+	// Locations are the locations in the query the error belongs to.
+	Locations []GraphQLLocation `json:"locations"`
+	// Path is the path of the field of the error in data, with field names and list indexes.
+	Path       []interface{}          `json:"path"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// GraphQLLocation is a location in a GraphQL query.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e *GraphQLError) Error() string {
+	msg := "graphql: " + e.Message
+	if len(e.Path) > 0 {
+		path := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			path[i] = fmt.Sprint(p)
+		}
+		msg += " at " + strings.Join(path, ".")
+	}
+	for _, l := range e.Locations {
+		msg += fmt.Sprintf(" (line %d, column %d)", l.Line, l.Column)
+	}
+	return msg
+}
+
+// GraphQL sets a GraphQL query with its variables as the body, which is sent with POST as JSON.
+// variables can be nil. Send it with EndGraphQL to get the data and errors of the response:
+//
+//      var data struct {
+//        Repository struct {
+//          Stars int `json:"stargazerCount"`
+//        } `json:"repository"`
+//      }
+//      resp, gqlResp, errs := goreq.New().
+//        Post("https://api.github.com/graphql").
+//        SetHeader("Authorization", "bearer token").
+//        GraphQL(`query($owner: String!, $name: String!) {
+//          repository(owner: $owner, name: $name) { stargazerCount }
+//        }`, map[string]interface{}{"owner": "smallnest", "name": "goreq"}).
+//        EndGraphQL(&data)
+//
+func (gr *GoReq) GraphQL(query string, variables map[string]interface{}) *GoReq {
+	gr.Method = POST
+	gr.bodyValue = &bodyValue{value: &graphQLRequest{Query: query, Variables: variables}, codec: jsonCodec{}}
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/json"
+	}
+	if gr.Header["Accept"] == "" {
+		gr.Header["Accept"] = "application/graphql-response+json, application/json"
+	}
+	return gr
+}
+
+// PersistedQuery sends the GraphQL query as an automatic persisted query: only the SHA-256 hash of the query is sent
+// in the persistedQuery extension, and EndGraphQL sends the query again with its hash if the server doesn't know the hash yet.
+// It must be called after GraphQL.
+func (gr *GoReq) PersistedQuery() *GoReq {
+	req := gr.graphQLRequest()
+	if req == nil {
+		gr.Errors = append(gr.Errors, errors.New("goreq: PersistedQuery must be called after GraphQL"))
+		return gr
+	}
+	hash := sha256.Sum256([]byte(req.Query))
+	persisted := *req
+	persisted.Extensions = map[string]interface{}{
+		"persistedQuery": map[string]interface{}{
+			"version":    1,
+			"sha256Hash": hex.EncodeToString(hash[:]),
+		},
+	}
+	gr.bodyValue = &bodyValue{value: &persisted, codec: jsonCodec{}}
+	return gr
+}
+
+// EndGraphQL sends the GraphQL query set by GraphQL and decodes the data of the response into data, if data is not nil.
+// The errors of the response are returned as *GraphQLError in the errors, together with the data which could be resolved.
+// The extensions of the response are in the returned GraphQLResponse.
+func (gr *GoReq) EndGraphQL(data interface{}) (Response, *GraphQLResponse, []error) {
+	req := gr.graphQLRequest()
+	if req == nil {
+		gr.Errors = append(gr.Errors, errors.New("goreq: EndGraphQL needs a query set by GraphQL"))
+		return nil, nil, gr.Errors
+	}
+
+	if req.Extensions["persistedQuery"] != nil {
+		// try the hash without the query first
+		hashOnly := *req
+		hashOnly.Query = ""
+		gr.bodyValue = &bodyValue{value: &hashOnly, codec: jsonCodec{}}
+		resp, gqlResp, errs := gr.sendGraphQL()
+		gr.bodyValue = &bodyValue{value: req, codec: jsonCodec{}}
+		if errs != nil {
+			return resp, nil, errs
+		}
+		if !persistedQueryNotFound(gqlResp) {
+			return gr.graphQLResult(resp, gqlResp, data)
+		}
+	}
+
+	resp, gqlResp, errs := gr.sendGraphQL()
+	if errs != nil {
+		return resp, nil, errs
+	}
+	return gr.graphQLResult(resp, gqlResp, data)
+}
+
+// graphQLRequest returns the GraphQL request set by GraphQL, or nil.
+func (gr *GoReq) graphQLRequest() *graphQLRequest {
+	if gr.bodyValue == nil {
+		return nil
+	}
+	req, _ := gr.bodyValue.value.(*graphQLRequest)
+	return req
+}
+
+// sendGraphQL sends the request and decodes the response.
+// A response with an error status is only decoded if it has GraphQL errors.
+func (gr *GoReq) sendGraphQL() (Response, *GraphQLResponse, []error) {
+	resp, body, errs := gr.EndBytes()
+	if errs != nil {
+		return nil, nil, errs
+	}
+	var gqlResp GraphQLResponse
+	err := json.Unmarshal(body, &gqlResp)
+	if resp.StatusCode >= 400 && (err != nil || len(gqlResp.Errors) == 0) {
+		err = fmt.Errorf("goreq: graphql responded %s", resp.Status)
+	}
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return resp, nil, gr.Errors
+	}
+	return resp, &gqlResp, nil
+}
+
+// graphQLResult decodes the data of gqlResp into data and returns its errors.
+func (gr *GoReq) graphQLResult(resp Response, gqlResp *GraphQLResponse, data interface{}) (Response, *GraphQLResponse, []error) {
+	if data != nil && len(gqlResp.Data) > 0 && string(gqlResp.Data) != "null" {
+		if err := json.Unmarshal(gqlResp.Data, data); err != nil {
+			gr.Errors = append(gr.Errors, err)
+		}
+	}
+	for _, err := range gqlResp.Errors {
+		gr.Errors = append(gr.Errors, err)
+	}
+	if len(gr.Errors) != 0 {
+		return resp, gqlResp, gr.Errors
+	}
+	return resp, gqlResp, nil
+}
+
+// persistedQueryNotFound reports whether the server doesn't know the hash of a persisted query.
+func persistedQueryNotFound(gqlResp *GraphQLResponse) bool {
+	for _, err := range gqlResp.Errors {
+		if err.Message == "PersistedQueryNotFound" || err.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+	return false
+}
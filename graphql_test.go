@@ -0,0 +1,146 @@
+package goreq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+const heroQuery = `query($episode: String!) { hero(episode: $episode) { name friends { name } } }`
+
+// newGraphQLServer starts a GraphQL server which knows the hero query and supports automatic persisted queries.
+func newGraphQLServer(t *testing.T) (*httptest.Server, *[]graphQLRequest) {
+	var (
+		mu        sync.Mutex
+		requests  []graphQLRequest
+		persisted = map[string]string{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != POST || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected POST of application/json | but got %s of %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		if pq, ok := req.Extensions["persistedQuery"].(map[string]interface{}); ok {
+			hash := pq["sha256Hash"].(string)
+			if req.Query == "" {
+				if req.Query = persisted[hash]; req.Query == "" {
+					fmt.Fprint(w, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+					return
+				}
+			} else {
+				sum := sha256.Sum256([]byte(req.Query))
+				if hex.EncodeToString(sum[:]) != hash {
+					t.Errorf("Expected the sha256 hash of the query | but got %s", hash)
+				}
+				persisted[hash] = req.Query
+			}
+		}
+
+		switch {
+		case req.Query != heroQuery:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":[{"message":"Syntax Error","locations":[{"line":1,"column":3}]}]}`)
+		case req.Variables["episode"] == "JEDI":
+			fmt.Fprint(w, `{"data":{"hero":{"name":"Luke","friends":[{"name":"Han"},{"name":"Leia"}]}},"extensions":{"cost":3}}`)
+		default:
+			fmt.Fprint(w, `{"data":{"hero":{"name":"R2-D2","friends":[{"name":"Luke"},null]}},
+				"errors":[{"message":"Name for character with ID 1002 could not be fetched.","locations":[{"line":1,"column":50}],"path":["hero","friends",1,"name"]}]}`)
+		}
+	}))
+	return ts, &requests
+}
+
+type heroData struct {
+	Hero struct {
+		Name    string
+		Friends []*struct{ Name string }
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	ts, requests := newGraphQLServer(t)
+	defer ts.Close()
+
+	var data heroData
+	_, gqlResp, errs := New().Post(ts.URL).GraphQL(heroQuery, map[string]interface{}{"episode": "JEDI"}).EndGraphQL(&data)
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if data.Hero.Name != "Luke" || len(data.Hero.Friends) != 2 || data.Hero.Friends[1].Name != "Leia" {
+		t.Errorf("Expected hero Luke with 2 friends | but got %+v", data.Hero)
+	}
+	if gqlResp.Extensions["cost"] != float64(3) {
+		t.Errorf("Expected extensions cost 3 | but got %v", gqlResp.Extensions)
+	}
+	if len(*requests) != 1 || (*requests)[0].Extensions != nil {
+		t.Errorf("Expected a request without extensions | but got %+v", *requests)
+	}
+
+	// partial data with an error
+	data = heroData{}
+	_, _, errs = New().Post(ts.URL).GraphQL(heroQuery, map[string]interface{}{"episode": "EMPIRE"}).EndGraphQL(&data)
+	if data.Hero.Name != "R2-D2" || len(data.Hero.Friends) != 2 || data.Hero.Friends[1] != nil {
+		t.Errorf("Expected hero R2-D2 with a missing friend | but got %+v", data.Hero)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("Expected a GraphQL error | but got %v", errs)
+	}
+	gqlErr, ok := errs[0].(*GraphQLError)
+	if !ok || !reflect.DeepEqual(gqlErr.Path, []interface{}{"hero", "friends", float64(1), "name"}) ||
+		!reflect.DeepEqual(gqlErr.Locations, []GraphQLLocation{{Line: 1, Column: 50}}) {
+		t.Errorf("Expected a GraphQLError with path and location | but got %#v", errs[0])
+	}
+	expected := "graphql: Name for character with ID 1002 could not be fetched. at hero.friends.1.name (line 1, column 50)"
+	if errs[0].Error() != expected {
+		t.Errorf("Expected error %q | but got %q", expected, errs[0].Error())
+	}
+
+	// a request error with a 400 response
+	resp, _, errs := New().Post(ts.URL).GraphQL("{ hero }", nil).EndGraphQL(nil)
+	if resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a 400 response | but got %v", resp)
+	}
+	if len(errs) != 1 || errs[0].Error() != "graphql: Syntax Error (line 1, column 3)" {
+		t.Errorf("Expected a syntax error | but got %v", errs)
+	}
+
+	if _, _, errs := New().Post(ts.URL).EndGraphQL(nil); errs == nil {
+		t.Error("Expected an error without a query")
+	}
+}
+
+func TestGraphQLPersistedQuery(t *testing.T) {
+	ts, requests := newGraphQLServer(t)
+	defer ts.Close()
+
+	query := New().Post(ts.URL).GraphQL(heroQuery, map[string]interface{}{"episode": "JEDI"}).PersistedQuery().Template()
+	for i := 0; i < 2; i++ {
+		var data heroData
+		if _, _, errs := query.Clone().EndGraphQL(&data); errs != nil || data.Hero.Name != "Luke" {
+			t.Fatalf("Expected hero Luke | but got %+v, %v", data.Hero, errs)
+		}
+	}
+
+	// the hash is registered with the query once, then sent alone
+	queries := make([]string, len(*requests))
+	for i, req := range *requests {
+		queries[i] = req.Query
+	}
+	if !reflect.DeepEqual(queries, []string{"", heroQuery, ""}) {
+		t.Errorf("Expected to send the query only when the hash is not found | but got %q", queries)
+	}
+}
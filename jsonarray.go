@@ -0,0 +1,219 @@
+package goreq
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// JSONElementError is returned by EachJSONElement when an element of the array can not be decoded.
+type JSONElementError struct {
+	// Index is the index of the element in the array.
+	Index int
+	// Offset is the offset in the response body before the element and the comma preceding it.
+	Offset int64
+	Err    error
+}
+
+func (e *JSONElementError) Error() string {
+	return fmt.Sprintf("goreq: json element %d at offset %d: %v", e.Index, e.Offset, e.Err)
+}
+
+// EachJSONElement sends the request and reads a JSON array in the response element by element with a json.Decoder,
+// calling fn with each element as soon as it is decoded, so huge arrays are processed in constant memory.
+// The array is the whole response if path is empty, or the value at path, whose names are separated by dots
+// and can be array indexes, such as "data.items" for {"data": {"items": [...]}}.
+// fn is a func(raw json.RawMessage) error, or a func(v T) error which gets each element decoded into a T:
+//
+//      resp, errs := goreq.New().
+//        Get("/exports/users").
+//        EachJSONElement("data.items", func(u User) error {
+//          return save(u)
+//        })
+//
+// A null value is an empty array. Reading stops at the end of the array, when an element can not be decoded,
+// which returns a *JSONElementError, or when fn returns an error, which is returned unless it is ErrStop.
+// The rest of the response after the array is not read.
+func (gr *GoReq) EachJSONElement(path string, fn interface{}) (Response, []error) {
+	call, err := jsonFunc(fn)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, gr.Errors
+	}
+
+	resp, errs := gr.send()
+	if errs != nil {
+		return nil, errs
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	if err := eachJSONElement(dec, path, call); err != nil && err != ErrStop {
+		gr.Errors = append(gr.Errors, err)
+		return resp, gr.Errors
+	}
+	return resp, nil
+}
+
+func eachJSONElement(dec *json.Decoder, path string, call func(decode func(v interface{}) error) error) error {
+	if path != "" {
+		for _, name := range strings.Split(path, ".") {
+			if err := seekJSON(dec, name); err != nil {
+				return fmt.Errorf("goreq: json path %s: %v", path, err)
+			}
+		}
+	}
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("goreq: json value at %q is not an array", path)
+	}
+	for i := 0; dec.More(); i++ {
+		err := call(func(v interface{}) error {
+			offset := dec.InputOffset()
+			if err := dec.Decode(v); err != nil {
+				return &JSONElementError{Index: i, Offset: offset, Err: err}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// seekJSON reads dec up to the value of the field name in an object, or of the element at index name in an array.
+func seekJSON(dec *json.Decoder, name string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if key == name {
+				return nil
+			}
+			if err := skipJSON(dec); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		index, err := strconv.Atoi(name)
+		if err != nil {
+			return fmt.Errorf("%s is not an array index", name)
+		}
+		for i := 0; dec.More(); i++ {
+			if i == index {
+				return nil
+			}
+			if err := skipJSON(dec); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("%s not found", name)
+}
+
+// skipJSON skips the next value of dec without keeping it in memory.
+func skipJSON(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonStreamWriter returns a func which writes v as JSON for SendJSONStream.
+// The elements of a slice, an array or a channel are marshaled one at a time.
+func jsonStreamWriter(v interface{}) func(w io.Writer) error {
+	rv := reflect.ValueOf(v)
+	_, marshaler := v.(json.Marshaler)
+	switch {
+	case marshaler, rv.Kind() == reflect.Slice && (rv.IsNil() || rv.Type().Elem().Kind() == reflect.Uint8):
+		// encoded as a whole, like a []byte into a base64 string
+	case rv.Kind() == reflect.Chan:
+		var sent int32
+		return func(w io.Writer) error {
+			if !atomic.CompareAndSwapInt32(&sent, 0, 1) {
+				return errors.New("goreq: the channel of SendJSONStream can not be sent again")
+			}
+			return writeJSONArray(w, true, func() (reflect.Value, bool) {
+				return rv.Recv()
+			})
+		}
+	case rv.Kind() == reflect.Slice, rv.Kind() == reflect.Array:
+		return func(w io.Writer) error {
+			i := 0
+			return writeJSONArray(w, false, func() (reflect.Value, bool) {
+				if i >= rv.Len() {
+					return reflect.Value{}, false
+				}
+				i++
+				return rv.Index(i - 1), true
+			})
+		}
+	}
+	return func(w io.Writer) error {
+		return jsonCodec{}.Encode(w, v)
+	}
+}
+
+// writeJSONArray writes the elements returned by next as a JSON array until next returns false.
+// If flush is true, each element is written to w as soon as it is returned.
+func writeJSONArray(w io.Writer, flush bool, next func() (reflect.Value, bool)) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	for i := 0; ; i++ {
+		elem, ok := next()
+		if !ok {
+			break
+		}
+		b, err := json.Marshal(elem.Interface())
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+		if flush {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	bw.WriteByte(']')
+	return bw.Flush()
+}
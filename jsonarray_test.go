@@ -0,0 +1,118 @@
+package goreq
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEachJSONElement(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/array":
+			fmt.Fprint(w, `[{"id":0},{"id":1},{"id":2}]`)
+		case "/nested":
+			fmt.Fprint(w, `{"meta":{"skip":[1,{"a":[2]}],"items":"x"},"data":{"total":3,"items":[{"id":0},{"id":1},{"id":2}]},"next":"ignored"}`)
+		case "/index":
+			fmt.Fprint(w, `{"pages":[{"items":[]},{"items":[{"id":0},{"id":1},{"id":2}]}]}`)
+		case "/null":
+			fmt.Fprint(w, `{"data":{"items":null}}`)
+		}
+	}))
+	defer ts.Close()
+
+	for _, c := range []struct {
+		path, jsonPath string
+		count          int
+	}{
+		{"/array", "", 3},
+		{"/nested", "data.items", 3},
+		{"/index", "pages.1.items", 3},
+		{"/null", "data.items", 0},
+	} {
+		var ids []int
+		_, errs := New().Get(ts.URL+c.path).EachJSONElement(c.jsonPath, func(item struct{ ID int }) error {
+			ids = append(ids, item.ID)
+			return nil
+		})
+		if errs != nil {
+			t.Errorf("Unexpected errors for %s: %s", c.path, errs)
+			continue
+		}
+		if len(ids) != c.count {
+			t.Errorf("Expected %d elements of %s | but got %v", c.count, c.path, ids)
+		}
+		for i, id := range ids {
+			if id != i {
+				t.Errorf("Expected element %d of %s | but got %d", i, c.path, id)
+			}
+		}
+	}
+
+	for _, jsonPath := range []string{"data.missing", "meta.items", "data.items.x"} {
+		_, errs := New().Get(ts.URL+"/nested").EachJSONElement(jsonPath, func(raw json.RawMessage) error {
+			return nil
+		})
+		if errs == nil {
+			t.Errorf("Expected an error for path %s", jsonPath)
+		}
+	}
+}
+
+func TestEachJSONElementStream(t *testing.T) {
+	const total = 100000
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[`)
+		for i := 0; i < total; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id":%d,"name":"user %d"}`, i, i)
+		}
+		fmt.Fprint(w, `]}`)
+	}))
+	defer ts.Close()
+
+	count := 0
+	_, errs := New().Get(ts.URL).EachJSONElement("data", func(raw json.RawMessage) error {
+		count++
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if count != total {
+		t.Errorf("Expected %d elements | but got %d", total, count)
+	}
+
+	count = 0
+	_, errs = New().Get(ts.URL).EachJSONElement("data", func(raw json.RawMessage) error {
+		count++
+		if count == 10 {
+			return ErrStop
+		}
+		return nil
+	})
+	if errs != nil || count != 10 {
+		t.Errorf("Expected to stop after 10 elements | but got %d, %v", count, errs)
+	}
+}
+
+func TestEachJSONElementDecodeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":0},{"id":"one"},{"id":2}]`)
+	}))
+	defer ts.Close()
+
+	_, errs := New().Get(ts.URL).EachJSONElement("", func(item struct{ ID int }) error {
+		return nil
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected a decode error | but got %v", errs)
+	}
+	if elementErr, ok := errs[0].(*JSONElementError); !ok || elementErr.Index != 1 || elementErr.Offset != 9 {
+		t.Errorf("Expected a JSONElementError of element 1 at offset 9 | but got %v", errs[0])
+	}
+}
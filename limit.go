@@ -0,0 +1,70 @@
+package goreq
+
+import (
+	"fmt"
+	"io"
+)
+
+// ResponseTooLargeError is returned when a response body exceeds the limit set by MaxResponseBytes or MaxDecompressedBytes.
+type ResponseTooLargeError struct {
+	// Limit is the exceeded limit in bytes
+	Limit int64
+	// Decompressed is true if the decompressed body exceeded the limit set by MaxDecompressedBytes
+	Decompressed bool
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.Decompressed {
+		return fmt.Sprintf("decompressed response body exceeds %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// MaxResponseBytes limits the size of the response body read by End and EndBytes.
+// GoReq stops reading once the body gets larger than n bytes and returns a *ResponseTooLargeError.
+// For a compressed response the limit applies to the bytes received; use MaxDecompressedBytes to limit the decoded size.
+// Zero or a negative n means no limit. With a limit set, debug mode logs responses without their body.
+func (gr *GoReq) MaxResponseBytes(n int64) *GoReq {
+	gr.maxResponseBytes = n
+	return gr
+}
+
+// MaxDecompressedBytes limits the size of a response body after decompression,
+// which protects against compression bombs from untrusted endpoints.
+// GoReq stops decoding once the body gets larger than n bytes and returns a *ResponseTooLargeError.
+// Zero or a negative n means no limit.
+func (gr *GoReq) MaxDecompressedBytes(n int64) *GoReq {
+	gr.maxDecompressed = n
+	return gr
+}
+
+// limitReader reads at most limit bytes and returns err if the underlying reader has more.
+type limitReader struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func newLimitReader(r io.ReadCloser, limit int64, decompressed bool) *limitReader {
+	return &limitReader{
+		ReadCloser: r,
+		remaining:  limit,
+		err:        &ResponseTooLargeError{Limit: limit, Decompressed: decompressed},
+	}
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+	// read one more byte than allowed to detect an oversized body
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
@@ -0,0 +1,87 @@
+package goreq
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseBytes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chunked") != "" {
+			// flushing first makes the response chunked, without Content-Length
+			w.(http.Flusher).Flush()
+		}
+		w.Write(bytes.Repeat([]byte("a"), 100))
+	}))
+	defer ts.Close()
+
+	_, body, errs := New().Get(ts.URL).MaxResponseBytes(100).EndBytes()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if len(body) != 100 {
+		t.Errorf("Expected 100 bytes | but got %d", len(body))
+	}
+
+	for _, chunked := range []string{"", "1"} {
+		_, _, errs = New().Get(ts.URL).Param("chunked", chunked).MaxResponseBytes(99).EndBytes()
+		if len(errs) != 1 {
+			t.Fatalf("Expected one error | but got %v", errs)
+		}
+		if e, ok := errs[0].(*ResponseTooLargeError); !ok || e.Limit != 99 || e.Decompressed {
+			t.Errorf("Expected *ResponseTooLargeError with limit 99 | but got %#v", errs[0])
+		}
+	}
+}
+
+func TestMaxResponseBytesDebug(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		w.Write(bytes.Repeat([]byte("a"), 100))
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	_, _, errs := New().Get(ts.URL).SetDebug(true).SetLogger(log.New(&logs, "", 0)).MaxResponseBytes(99).EndBytes()
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error | but got %v", errs)
+	}
+	if _, ok := errs[0].(*ResponseTooLargeError); !ok {
+		t.Errorf("Expected *ResponseTooLargeError in debug mode | but got %#v", errs[0])
+	}
+	if !strings.Contains(logs.String(), "HTTP Response") || strings.Contains(logs.String(), "aaaa") {
+		t.Errorf("Expected the response to be logged without its body | but got %s", logs.String())
+	}
+}
+
+func TestMaxDecompressedBytes(t *testing.T) {
+	bomb := compressBodyOrFail(t, Gzip, make([]byte, 1<<20))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", Gzip)
+		w.Write(bomb)
+	}))
+	defer ts.Close()
+
+	_, _, errs := New().Get(ts.URL).
+		MaxResponseBytes(int64(len(bomb))).
+		MaxDecompressedBytes(1 << 10).
+		EndBytes()
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error | but got %v", errs)
+	}
+	if e, ok := errs[0].(*ResponseTooLargeError); !ok || e.Limit != 1<<10 || !e.Decompressed {
+		t.Errorf("Expected decompressed *ResponseTooLargeError with limit 1024 | but got %#v", errs[0])
+	}
+
+	_, body, errs := New().Get(ts.URL).MaxDecompressedBytes(1 << 20).EndBytes()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if len(body) != 1<<20 {
+		t.Errorf("Expected %d bytes | but got %d", 1<<20, len(body))
+	}
+}
@@ -0,0 +1,354 @@
+package goreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Link is a link of a Link header, see RFC 8288.
+type Link struct {
+	// URI is the target of the link, resolved against the request url.
+	URI string
+	// Rel is the relation type of the link, such as "next". A link with many relation types is split into a Link for each one.
+	Rel string
+	// Type is the media type of the target.
+	Type string
+	// Title is the title of the link, decoded from title* if it is set.
+	Title string
+	// Anchor is the context of the link, resolved against the request url. It is empty if the context is the requested resource.
+	Anchor string
+	// Params are the other parameters, such as hreflang and media, by lower case name. Values of name* parameters are decoded and kept as name.
+	Params map[string]string
+}
+
+// Links are the links of a response.
+type Links []Link
+
+// Rel returns the first link with relation type rel, which is compared case-insensitively.
+func (links Links) Rel(rel string) (Link, bool) {
+	for _, link := range links {
+		if strings.EqualFold(link.Rel, rel) {
+			return link, true
+		}
+	}
+	return Link{}, false
+}
+
+// ParseLinks returns the links of the Link headers of resp. Relative URIs are resolved against the url of the request of resp.
+// Invalid links are skipped.
+//
+// For example:
+//    resp, _, _ := goreq.New().Get("https://api.github.com/repos/smallnest/goreq/issues").End()
+//    if next, ok := goreq.ParseLinks(resp).Rel("next"); ok {
+//        fmt.Println(next.URI)
+//    }
+//
+func ParseLinks(resp Response) Links {
+	var base *url.URL
+	if resp.Request != nil {
+		base = resp.Request.URL
+	}
+	var links Links
+	for _, header := range resp.Header["Link"] {
+		links = append(links, parseLinkHeader(header, base)...)
+	}
+	return links
+}
+
+// Follow returns a new GoReq to GET the link of resp with relation type rel.
+// It is a Clone of the GoReq with the url of the link, so it has the same headers, basic auth, cookies and settings,
+// but no query and no body. If resp has no such link, the new GoReq has an error.
+// Like http.Client does on redirects, the Authorization and Cookie headers, basic auth and cookies are only kept
+// if the link has the same origin (scheme, host and port) as the first request of resp.
+//
+// For example:
+//    req := goreq.New().SetHeader("Authorization", "token foo")
+//    resp, body, _ := req.Get("https://api.github.com/repos/smallnest/goreq/issues").End()
+//    resp, body, _ = req.Follow(resp, "next").End()
+func (gr *GoReq) Follow(resp Response, rel string) *GoReq {
+	next := gr.Clone()
+	next.Method = GET
+	next.Errors = nil
+	next.Data = make(map[string]interface{})
+	next.FormData = url.Values{}
+	next.QueryData = url.Values{}
+	next.RawStringData = ""
+	next.RawBytesData = make([]byte, 0)
+	next.FilePath = ""
+	next.FileParam = ""
+	next.bodyValue = nil
+	next.templateVars = nil
+	delete(next.Header, "Content-Type")
+
+	link, ok := ParseLinks(resp).Rel(rel)
+	if !ok {
+		next.URL = ""
+		next.Errors = append(next.Errors, fmt.Errorf("goreq: no link with rel=%q", rel))
+		return next
+	}
+	next.URL = link.URI
+	if target, err := url.Parse(link.URI); err != nil || !sameOrigin(resp, target) {
+		next.dropCredentials()
+	}
+	return next
+}
+
+// credentialHeaders are the headers which are not sent to another origin, like http.Client does on redirects.
+var credentialHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+// sameOrigin reports whether target has the same scheme, host and port as the first request of resp, before any redirect.
+func sameOrigin(resp Response, target *url.URL) bool {
+	if resp == nil || resp.Request == nil {
+		return false
+	}
+	req := resp.Request
+	for req.Response != nil && req.Response.Request != nil {
+		req = req.Response.Request
+	}
+	return strings.EqualFold(req.URL.Scheme, target.Scheme) && hostPort(req.URL) == hostPort(target)
+}
+
+// hostPort returns the lower case host and the port of u, which is the default port of its scheme if u has none.
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http", "ws":
+			port = "80"
+		case "https", "wss":
+			port = "443"
+		}
+	}
+	return strings.ToLower(u.Hostname()) + ":" + port
+}
+
+// dropCredentials removes the credential headers, basic auth and cookies of gr.
+func (gr *GoReq) dropCredentials() {
+	for k := range gr.Header {
+		for _, h := range credentialHeaders {
+			if http.CanonicalHeaderKey(k) == h {
+				delete(gr.Header, k)
+			}
+		}
+	}
+	gr.BasicAuth = struct{ Username, Password string }{}
+	gr.Cookies = make([]*http.Cookie, 0)
+}
+
+// parseLinkHeader parses the value of a Link header:
+//    Link = #( "<" URI-Reference ">" *( OWS ";" OWS link-param ) )
+//    link-param = token BWS [ "=" BWS ( token / quoted-string ) ]
+func parseLinkHeader(header string, base *url.URL) []Link {
+	var links []Link
+	p := &linkParser{s: header}
+	for {
+		p.skip(" \t,")
+		if p.eof() {
+			return links
+		}
+		link, ok := p.link()
+		if !ok {
+			// skip to the next link
+			p.skipLink()
+			continue
+		}
+		links = append(links, link.resolve(base)...)
+	}
+}
+
+// rawLink is a parsed link before the relation types are split and the URIs are resolved.
+type rawLink struct {
+	uri    string
+	rel    string
+	hasRel bool
+	params map[string]string
+}
+
+// resolve returns a Link for each relation type of l with the URIs resolved against base.
+func (l *rawLink) resolve(base *url.URL) []Link {
+	uri, err := resolveURI(base, l.uri)
+	if err != nil {
+		return nil
+	}
+	link := Link{URI: uri, Params: map[string]string{}}
+	for name, value := range l.params {
+		switch name {
+		case "type":
+			link.Type = value
+		case "title":
+			link.Title = value
+		case "anchor":
+			if link.Anchor, err = resolveURI(base, value); err != nil {
+				return nil
+			}
+		default:
+			link.Params[name] = value
+		}
+	}
+
+	var links []Link
+	for _, rel := range strings.Fields(l.rel) {
+		link.Rel = rel
+		links = append(links, link)
+	}
+	return links
+}
+
+func resolveURI(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String(), nil
+}
+
+type linkParser struct {
+	s   string
+	pos int
+}
+
+func (p *linkParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *linkParser) skip(chars string) {
+	for !p.eof() && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// skipLink skips to the comma after the current link, outside of quoted strings and URIs.
+func (p *linkParser) skipLink() {
+	for !p.eof() && p.s[p.pos] != ',' {
+		switch p.s[p.pos] {
+		case '"':
+			p.quoted()
+		case '<':
+			if i := strings.IndexByte(p.s[p.pos:], '>'); i >= 0 {
+				p.pos += i + 1
+			} else {
+				p.pos = len(p.s)
+			}
+		default:
+			p.pos++
+		}
+	}
+}
+
+// link parses a link up to the comma after it.
+func (p *linkParser) link() (*rawLink, bool) {
+	if p.s[p.pos] != '<' {
+		return nil, false
+	}
+	end := strings.IndexByte(p.s[p.pos:], '>')
+	if end < 0 {
+		return nil, false
+	}
+	link := &rawLink{uri: strings.TrimSpace(p.s[p.pos+1 : p.pos+end]), params: map[string]string{}}
+	p.pos += end + 1
+
+	for {
+		p.skip(" \t")
+		if p.eof() || p.s[p.pos] == ',' {
+			break
+		}
+		if p.s[p.pos] != ';' {
+			return nil, false
+		}
+		p.pos++
+		p.skip(" \t")
+		name := strings.ToLower(p.token())
+		if name == "" {
+			return nil, false
+		}
+		p.skip(" \t")
+		var value string
+		if !p.eof() && p.s[p.pos] == '=' {
+			p.pos++
+			p.skip(" \t")
+			if !p.eof() && p.s[p.pos] == '"' {
+				value = p.quoted()
+			} else {
+				value = p.token()
+			}
+		}
+
+		// the first rel wins, see RFC 8288 section 3.3
+		if name == "rel" {
+			if !link.hasRel {
+				link.rel, link.hasRel = value, true
+			}
+			continue
+		}
+		// name* is preferred to name, see RFC 8288 section 3.4.1
+		if strings.HasSuffix(name, "*") {
+			decoded, ok := decodeExtValue(value)
+			if !ok {
+				continue
+			}
+			link.params[strings.TrimSuffix(name, "*")] = decoded
+			continue
+		}
+		if _, ok := link.params[name]; !ok {
+			link.params[name] = value
+		}
+	}
+	return link, link.hasRel
+}
+
+// token reads a token.
+func (p *linkParser) token() string {
+	start := p.pos
+	for !p.eof() && isTokenChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// quoted reads a quoted string, unescaping quoted pairs.
+func (p *linkParser) quoted() string {
+	var b strings.Builder
+	p.pos++ // opening quote
+	for !p.eof() {
+		c := p.s[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return b.String()
+		case '\\':
+			if !p.eof() {
+				b.WriteByte(p.s[p.pos])
+				p.pos++
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// isTokenChar reports whether c is a tchar of RFC 7230 section 3.2.6.
+func isTokenChar(c byte) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+// decodeExtValue decodes an ext-value of RFC 8187 such as UTF-8'en'%e2%82%ac%20rates.
+func decodeExtValue(value string) (string, bool) {
+	parts := strings.SplitN(value, "'", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[0], "utf-8") {
+		return "", false
+	}
+	decoded, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return "", false
+	}
+	return decoded, true
+}
@@ -0,0 +1,127 @@
+package goreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	reqURL, _ := url.Parse("https://example.com/api/items?page=2")
+	resp := &http.Response{
+		Header: http.Header{"Link": {
+			`<?page=3>; rel="next"; type="application/json", </api/items?page=1>; REL="prev first"; title="back, to \"start\""`,
+			`<https://other.example/terms>; rel=license; anchor="#legal"; hreflang=en; title=ignored; title*=UTF-8'de'n%c3%a4chstes`,
+			`<broken; rel=next, no-uri; rel=next, <https://example.com/norel>; type=text/html, <https://example.com/x>; rel="x" rel="y"`,
+			`<https://example.com/dup>; rel=alternate; rel=other; media="screen"`,
+		}},
+		Request: &http.Request{URL: reqURL},
+	}
+
+	links := ParseLinks(resp)
+	expected := Links{
+		{URI: "https://example.com/api/items?page=3", Rel: "next", Type: "application/json", Params: map[string]string{}},
+		{URI: "https://example.com/api/items?page=1", Rel: "prev", Title: `back, to "start"`, Params: map[string]string{}},
+		{URI: "https://example.com/api/items?page=1", Rel: "first", Title: `back, to "start"`, Params: map[string]string{}},
+		{URI: "https://other.example/terms", Rel: "license", Title: "nächstes", Anchor: "https://example.com/api/items?page=2#legal",
+			Params: map[string]string{"hreflang": "en"}},
+		{URI: "https://example.com/dup", Rel: "alternate", Params: map[string]string{"media": "screen"}},
+	}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("Expected links %+v | but got %+v", expected, links)
+	}
+
+	if link, ok := links.Rel("FIRST"); !ok || link.URI != "https://example.com/api/items?page=1" {
+		t.Errorf("Expected link first | but got %+v", link)
+	}
+	if _, ok := links.Rel("last"); ok {
+		t.Error("Expected no link last")
+	}
+}
+
+func TestFollow(t *testing.T) {
+	// another origin gets the headers, but no credentials
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("API-Key") != "fookey" {
+			t.Errorf("Expected Header API-Key -> fookey | but got %s", r.Header.Get("API-Key"))
+		}
+		if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+			t.Errorf("Expected no credentials for another origin | but got %v", r.Header)
+		}
+		fmt.Fprint(w, "other")
+	}))
+	defer other.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("API-Key") != "fookey" {
+			t.Errorf("Expected Header API-Key -> fookey | but got %s", r.Header.Get("API-Key"))
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("Expected basic auth user:pass | but got %s:%s", user, pass)
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s1" {
+			t.Errorf("Expected cookie session=s1 | but got %v", cookie)
+		}
+		switch r.URL.Path {
+		case "/items":
+			if r.Method != POST || r.URL.Query().Get("q") != "go" {
+				t.Errorf("Expected POST /items?q=go | but got %s %s", r.Method, r.URL)
+			}
+			w.Header().Set("Link", `</items/7>; rel="item", <related>; rel="related", <`+other.URL+`>; rel="other"`)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			if r.Method != GET || r.URL.RawQuery != "" || r.ContentLength > 0 {
+				t.Errorf("Expected GET without query and body | but got %s %s", r.Method, r.URL)
+			}
+			fmt.Fprint(w, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	req := New().
+		SetHeader("API-Key", "fookey").
+		SetBasicAuth("user", "pass").
+		AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+	resp, _, errs := req.Post(ts.URL + "/items").Query("q=go").SendMapString(`{"name":"goreq"}`).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+
+	for rel, path := range map[string]string{"item": "/items/7", "related": "/related", "other": "other"} {
+		_, body, errs := req.Follow(resp, rel).End()
+		if errs != nil || body != path {
+			t.Errorf("Expected body %s | but got %s, %v", path, body, errs)
+		}
+	}
+
+	if _, _, errs := req.Follow(resp, "next").End(); errs == nil {
+		t.Error("Expected an error for a missing link")
+	}
+	if req.Method != POST || req.QueryData.Get("q") != "go" || req.BasicAuth.Username != "user" || len(req.Cookies) != 1 {
+		t.Errorf("Expected the GoReq to be unchanged | but got %s %v", req.Method, req.QueryData)
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	first, _ := url.Parse("https://Example.com/login")
+	redirected, _ := url.Parse("https://other.example/home")
+	resp := &http.Response{Request: &http.Request{URL: redirected, Response: &http.Response{Request: &http.Request{URL: first}}}}
+
+	cases := map[string]bool{
+		"https://example.com:443/items": true,
+		"https://example.com/":          true,
+		"http://example.com/items":      false,
+		"https://example.com:8443/":     false,
+		"https://other.example/items":   false,
+		"https://api.example.com/items": false,
+	}
+	for target, expected := range cases {
+		u, _ := url.Parse(target)
+		if sameOrigin(resp, u) != expected {
+			t.Errorf("Expected same origin %v for %s | but got %v", expected, target, !expected)
+		}
+	}
+}
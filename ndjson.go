@@ -0,0 +1,122 @@
+package goreq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ErrStop can be returned by the callback of a streaming method such as EachJSONLine to stop reading the response without an error.
+var ErrStop = errors.New("goreq: stop")
+
+// JSONLineError is returned by EachJSONLine when a line can not be decoded.
+type JSONLineError struct {
+	// Line is the line number, starting at 1.
+	Line int
+	Err  error
+}
+
+func (e *JSONLineError) Error() string {
+	return fmt.Sprintf("goreq: json line %d: %v", e.Line, e.Err)
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// EachJSONLine sends the request and reads the response as newline delimited JSON (NDJSON or JSON Lines),
+// calling fn with each line as soon as it is received, so the body is never held in memory.
+// fn is a func(raw json.RawMessage) error, or a func(v T) error which gets each line decoded into a T:
+//
+//      type Entry struct {
+//        Time    time.Time `json:"time"`
+//        Message string    `json:"message"`
+//      }
+//      resp, errs := goreq.New().
+//        Get("/logs/search?q=timeout").
+//        EachJSONLine(func(e Entry) error {
+//          fmt.Println(e.Time, e.Message)
+//          return nil
+//        })
+//
+// Empty lines are skipped. Reading stops at the end of the body, when a line can not be decoded, which returns a *JSONLineError
+// with the line number, or when fn returns an error, which is returned unless it is ErrStop.
+func (gr *GoReq) EachJSONLine(fn interface{}) (Response, []error) {
+	call, err := jsonFunc(fn)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, gr.Errors
+	}
+
+	resp, errs := gr.send()
+	if errs != nil {
+		return nil, errs
+	}
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if raw := bytes.TrimSpace(b); len(raw) > 0 {
+			err := call(func(v interface{}) error {
+				if err := json.Unmarshal(raw, v); err != nil {
+					return &JSONLineError{Line: line, Err: err}
+				}
+				return nil
+			})
+			if err != nil {
+				if err == ErrStop {
+					return resp, nil
+				}
+				gr.Errors = append(gr.Errors, err)
+				return resp, gr.Errors
+			}
+		}
+		if err == io.EOF {
+			return resp, nil
+		}
+		if err != nil {
+			gr.Errors = append(gr.Errors, err)
+			return resp, gr.Errors
+		}
+	}
+}
+
+// jsonFunc returns a function which calls fn with a value decoded by decode.
+// fn is a func(raw json.RawMessage) error or a func(v T) error.
+func jsonFunc(fn interface{}) (func(decode func(v interface{}) error) error, error) {
+	if f, ok := fn.(func(raw json.RawMessage) error); ok {
+		return func(decode func(v interface{}) error) error {
+			var raw json.RawMessage
+			if err := decode(&raw); err != nil {
+				return err
+			}
+			return f(raw)
+		}, nil
+	}
+
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 || t.Out(0) != errorType {
+		return nil, fmt.Errorf("goreq: %T is not a func(v T) error", fn)
+	}
+	in := t.In(0)
+	return func(decode func(v interface{}) error) error {
+		ptr := reflect.New(in)
+		if err := decode(ptr.Interface()); err != nil {
+			return err
+		}
+		return callError(v, ptr.Elem())
+	}, nil
+}
+
+// callError calls fn with arg and returns its error.
+func callError(fn reflect.Value, arg reflect.Value) error {
+	out := fn.Call([]reflect.Value{arg})
+	if err, _ := out[0].Interface().(error); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package goreq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type logEntry struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func TestEachJSONLine(t *testing.T) {
+	received := make(chan string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"level":"info","message":"first"}`)
+		fmt.Fprintln(w)
+		w.(http.Flusher).Flush()
+		// the first line is handled before the rest of the body is sent
+		if message := <-received; message != "first" {
+			t.Errorf("Expected the first line | but got %s", message)
+		}
+		fmt.Fprint(w, "{\"level\":\"error\",\"message\":\"second\"}\r\n")
+		fmt.Fprint(w, `{"level":"info","message":"third"}`)
+	}))
+	defer ts.Close()
+
+	var entries []logEntry
+	_, errs := New().Get(ts.URL).EachJSONLine(func(e logEntry) error {
+		entries = append(entries, e)
+		if len(entries) == 1 {
+			received <- e.Message
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if len(entries) != 3 || entries[1] != (logEntry{"error", "second"}) || entries[2].Message != "third" {
+		t.Errorf("Expected 3 entries | but got %+v", entries)
+	}
+}
+
+func TestEachJSONLineStop(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 1000; i++ {
+			fmt.Fprintf(w, "{\"id\":%d}\n", i)
+		}
+	}))
+	defer ts.Close()
+
+	count := 0
+	_, errs := New().Get(ts.URL).EachJSONLine(func(raw json.RawMessage) error {
+		count++
+		if count == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+	if count != 2 {
+		t.Errorf("Expected to stop after 2 lines | but got %d", count)
+	}
+
+	errFailed := errors.New("failed")
+	_, errs = New().Get(ts.URL).EachJSONLine(func(e *struct{ ID int }) error {
+		if e.ID == 5 {
+			return errFailed
+		}
+		return nil
+	})
+	if len(errs) != 1 || errs[0] != errFailed {
+		t.Errorf("Expected the error of the callback | but got %v", errs)
+	}
+}
+
+func TestEachJSONLineDecodeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "{\"level\":\"info\"}\n\n{\"level\":\n{\"level\":\"error\"}\n")
+	}))
+	defer ts.Close()
+
+	count := 0
+	_, errs := New().Get(ts.URL).EachJSONLine(func(e logEntry) error {
+		count++
+		return nil
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected a decode error | but got %v", errs)
+	}
+	lineErr, ok := errs[0].(*JSONLineError)
+	if !ok || lineErr.Line != 3 {
+		t.Errorf("Expected a JSONLineError at line 3 | but got %v", errs[0])
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 line before the error | but got %d", count)
+	}
+
+	_, errs = New().Get(ts.URL).EachJSONLine(func(e logEntry) {})
+	if errs == nil {
+		t.Error("Expected an error for a callback without error result")
+	}
+}
@@ -0,0 +1,231 @@
+package goreq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// PageConfig is used to config Paginate.
+type PageConfig struct {
+	// Next extracts the next page from a response and its body, e.g. a next cursor field in the body.
+	// It returns the url of the next page, or the cursor if CursorParam is set, and "" after the last page.
+	// If it is nil, the next page is the url of the Link header with rel="next".
+	Next func(resp Response, body []byte) (string, error)
+	// Query parameter the cursor returned by Next is sent in
+	CursorParam string
+	// Path of the items of a page in a JSON body, such as "data.items". The whole body is the items if it is empty.
+	Path string
+	// Max pages to fetch, 0 for all pages
+	MaxPages int
+	// Max pages handled at the same time. The pages are handled one by one in order if it is 0 or 1.
+	Concurrency int
+}
+
+// next returns the next page of resp.
+func (c *PageConfig) next(resp Response, body []byte) (string, error) {
+	if c.Next == nil {
+		link, _ := ParseLinks(resp).Rel("next")
+		return link.URI, nil
+	}
+	return c.Next(resp, body)
+}
+
+// PageError is returned by Paginate when a page responds an error status or can not be decoded.
+type PageError struct {
+	// Page is the number of the page, starting at 1.
+	Page int
+	// URL is the url of the page.
+	URL string
+	Err error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("goreq: page %d %s: %v", e.Page, e.URL, e.Err)
+}
+
+// Paginate sends the request and follows the next pages, calling fn with the items of each page.
+// fn is a func(items []T) error, which gets each page decoded into a []T by the codec of its Content-Type:
+//
+//      errs := goreq.New().
+//        Get("https://api.github.com/repos/smallnest/goreq/issues").
+//        Paginate(goreq.PageConfig{MaxPages: 10}, func(issues []Issue) error {
+//          return save(issues)
+//        })
+//
+// The next page is the Link header with rel="next" (RFC 8288) by default. For a cursor in the body, set config.Next
+// and config.CursorParam:
+//
+//      errs := goreq.New().
+//        Get("http://example.com/users").
+//        Paginate(goreq.PageConfig{
+//          Next: func(resp goreq.Response, body []byte) (string, error) {
+//            var page struct{ NextCursor string `json:"next_cursor"` }
+//            err := json.Unmarshal(body, &page)
+//            return page.NextCursor, err
+//          },
+//          CursorParam: "cursor",
+//          Path:        "users",
+//        }, func(users []User) error {
+//          return save(users)
+//        })
+//
+// The pages are fetched one after another. If config.Concurrency is more than 1, up to Concurrency pages are handled
+// at the same time by fn while the next pages are fetched, so fn must be safe for concurrent use.
+// Like http.Client does on redirects, pages of another origin than the first page are fetched without the Authorization
+// and Cookie headers, basic auth and cookies. The pages are fetched by a Clone of the GoReq, which is left unchanged.
+//
+// Paginate stops after the last page or config.MaxPages pages, when a page responds an error status or can not be
+// decoded, which returns a *PageError, or when fn returns an error, which is returned unless it is ErrStop.
+// Errors of sending a request and of config.Next are returned as they are.
+func (gr *GoReq) Paginate(config PageConfig, fn interface{}) []error {
+	// the pages are fetched by a clone, so the url and query of the next pages are not left in gr
+	gr = gr.Clone()
+	call, err := pageFunc(fn)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return gr.Errors
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      chan struct{}
+	)
+	if config.Concurrency > 1 {
+		sem = make(chan struct{}, config.Concurrency)
+	}
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	var first Response
+	for page := 1; config.MaxPages <= 0 || page <= config.MaxPages; page++ {
+		resp, body, errs := gr.EndBytes()
+		if errs != nil {
+			wg.Wait()
+			return errs
+		}
+		if first == nil {
+			first = resp
+		}
+		pageURL := resp.Request.URL.String()
+		if resp.StatusCode >= 400 {
+			fail(&PageError{Page: page, URL: pageURL, Err: fmt.Errorf("responded %s", resp.Status)})
+			break
+		}
+
+		handle := func(page int, contentType string, body []byte) error {
+			return call(func(v interface{}) error {
+				if err := decodePage(config.Path, contentType, body, v); err != nil {
+					return &PageError{Page: page, URL: pageURL, Err: err}
+				}
+				return nil
+			})
+		}
+		if sem == nil {
+			if err := handle(page, resp.Header.Get("Content-Type"), body); err != nil {
+				fail(err)
+				break
+			}
+		} else {
+			sem <- struct{}{}
+			if failed() {
+				<-sem
+				break
+			}
+			wg.Add(1)
+			go func(page int, contentType string, body []byte) {
+				defer wg.Done()
+				if err := handle(page, contentType, body); err != nil {
+					fail(err)
+				}
+				<-sem
+			}(page, resp.Header.Get("Content-Type"), body)
+		}
+
+		next, err := config.next(resp, body)
+		if err != nil {
+			fail(err)
+			break
+		}
+		if next == "" {
+			break
+		}
+		if config.CursorParam != "" {
+			gr.QueryData.Set(config.CursorParam, next)
+			continue
+		}
+		nextURL, err := resp.Request.URL.Parse(next)
+		if err != nil {
+			fail(err)
+			break
+		}
+		if !sameOrigin(first, nextURL) {
+			gr.dropCredentials()
+		}
+		// the url of the next page has the whole query and is not a template
+		gr.URL = nextURL.String()
+		gr.QueryData = url.Values{}
+		gr.templateVars = nil
+	}
+	wg.Wait()
+
+	if firstErr != nil && firstErr != ErrStop {
+		gr.Errors = append(gr.Errors, firstErr)
+		return gr.Errors
+	}
+	return nil
+}
+
+// decodePage decodes the items at path of a page body into v.
+func decodePage(path, contentType string, body []byte, v interface{}) error {
+	if path == "" {
+		codec := LookupCodec(contentType)
+		if codec == nil {
+			codec = jsonCodec{}
+		}
+		return codec.Decode(bytes.NewReader(body), v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for _, name := range strings.Split(path, ".") {
+		if err := seekJSON(dec, name); err != nil {
+			return fmt.Errorf("json path %s: %v", path, err)
+		}
+	}
+	return dec.Decode(v)
+}
+
+// pageFunc returns a function which calls fn with a page decoded by decode. fn is a func(items []T) error.
+func pageFunc(fn interface{}) (func(decode func(v interface{}) error) error, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("goreq: %T is not a func(items []T) error", fn)
+	}
+	t := v.Type()
+	if t.NumIn() != 1 || t.In(0).Kind() != reflect.Slice || t.NumOut() != 1 || t.Out(0) != errorType {
+		return nil, fmt.Errorf("goreq: %T is not a func(items []T) error", fn)
+	}
+	in := t.In(0)
+	return func(decode func(v interface{}) error) error {
+		ptr := reflect.New(in)
+		if err := decode(ptr.Interface()); err != nil {
+			return err
+		}
+		return callError(v, ptr.Elem())
+	}, nil
+}
@@ -0,0 +1,268 @@
+package goreq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type pageItem struct {
+	ID int `json:"id"`
+}
+
+// newPageServer serves 5 pages of 2 items, linked with Link headers on /links and with a cursor in the body on /cursor.
+func newPageServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		} else if c := r.URL.Query().Get("cursor"); c != "" {
+			page, _ = strconv.Atoi(c)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/cursor" {
+			if r.URL.Query().Get("size") != "2" {
+				t.Errorf("Expected query size=2 | but got %s", r.URL.RawQuery)
+			}
+			next := ""
+			if page < 5 {
+				next = strconv.Itoa(page + 1)
+			}
+			fmt.Fprintf(w, `{"data":{"items":[{"id":%d},{"id":%d}]},"next_cursor":%q}`, page*2-1, page*2, next)
+			return
+		}
+		if page < 5 {
+			w.Header().Add("Link", fmt.Sprintf(`</links?page=%d&size=2>; rel="next", </links?page=5&size=2>; rel="last"`, page+1))
+		}
+		if page > 1 {
+			w.Header().Add("Link", `<http://example.com/first>; rel="first prev"`)
+		}
+		fmt.Fprintf(w, `[{"id":%d},{"id":%d}]`, page*2-1, page*2)
+	}))
+}
+
+func TestPaginateLinks(t *testing.T) {
+	ts := newPageServer(t)
+	defer ts.Close()
+
+	var ids []int
+	errs := New().Get(ts.URL+"/links").Query("size=2").Paginate(PageConfig{}, func(items []pageItem) error {
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("Expected ids 1 to 10 | but got %v", ids)
+	}
+
+	pages := 0
+	errs = New().Get(ts.URL+"/links").Paginate(PageConfig{MaxPages: 3}, func(items []pageItem) error {
+		pages++
+		return nil
+	})
+	if errs != nil || pages != 3 {
+		t.Errorf("Expected 3 pages | but got %d, %v", pages, errs)
+	}
+
+	pages = 0
+	errs = New().Get(ts.URL+"/links").Paginate(PageConfig{}, func(items []pageItem) error {
+		pages++
+		if pages == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+	if errs != nil || pages != 2 {
+		t.Errorf("Expected to stop after 2 pages | but got %d, %v", pages, errs)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	ts := newPageServer(t)
+	defer ts.Close()
+
+	var ids []int
+	req := New().Get(ts.URL + "/cursor").Query("size=2")
+	errs := req.Paginate(PageConfig{
+		Next: func(resp Response, body []byte) (string, error) {
+			var page struct {
+				NextCursor string `json:"next_cursor"`
+			}
+			err := json.Unmarshal(body, &page)
+			return page.NextCursor, err
+		},
+		CursorParam: "cursor",
+		Path:        "data.items",
+	}, func(items []pageItem) error {
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("Expected ids 1 to 10 | but got %v", ids)
+	}
+	if req.QueryData.Encode() != "size=2" {
+		t.Errorf("Expected Paginate to leave the query unchanged | but got %s", req.QueryData.Encode())
+	}
+}
+
+func TestPaginateConcurrency(t *testing.T) {
+	ts := newPageServer(t)
+	defer ts.Close()
+
+	var (
+		mu            sync.Mutex
+		ids           []int
+		running, peak int32
+	)
+	errs := New().Get(ts.URL+"/links").Paginate(PageConfig{Concurrency: 2}, func(items []pageItem) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		mu.Lock()
+		if n > peak {
+			peak = n
+		}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if len(ids) != 10 {
+		t.Errorf("Expected 10 items | but got %v", ids)
+	}
+	if peak != 2 {
+		t.Errorf("Expected 2 pages handled at the same time | but got %d", peak)
+	}
+
+	failure := errors.New("failure")
+	errs = New().Get(ts.URL+"/links").Paginate(PageConfig{Concurrency: 3}, func(items []pageItem) error {
+		return failure
+	})
+	if len(errs) != 1 || errs[0] != failure {
+		t.Errorf("Expected the error of the function | but got %v", errs)
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Link", `<?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"id":"one"}]`)
+	}))
+	defer ts.Close()
+
+	errs := New().Get(ts.URL).Paginate(PageConfig{}, func(items []pageItem) error {
+		return nil
+	})
+	if pageErr, ok := errs[0].(*PageError); !ok || pageErr.Page != 1 {
+		t.Errorf("Expected a PageError of page 1 | but got %v", errs)
+	}
+
+	errs = New().Get(ts.URL).Paginate(PageConfig{}, func(raw []json.RawMessage) error {
+		return nil
+	})
+	if pageErr, ok := errs[0].(*PageError); !ok || pageErr.Page != 2 || pageErr.URL != ts.URL+"?page=2" {
+		t.Errorf("Expected a PageError of page 2 | but got %v", errs)
+	}
+
+	if errs := New().Get(ts.URL).Paginate(PageConfig{}, func(item pageItem) error { return nil }); errs == nil {
+		t.Error("Expected an error for a function without a slice")
+	}
+}
+
+func TestPaginateTemplate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path + " " + r.URL.Query().Get("filter") {
+		case "/items/books ":
+			w.Header().Set("Link", `</items/books?filter={"after":1}>; rel="next"`)
+			fmt.Fprint(w, `[{"id":1}]`)
+		case `/items/books {"after":1}`:
+			fmt.Fprint(w, `[{"id":2}]`)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.RequestURI())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	var ids []int
+	req := New().Get(ts.URL+"/items/{kind}").PathParam("kind", "books")
+	errs := req.Paginate(PageConfig{}, func(items []pageItem) error {
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("Expected ids 1 and 2 | but got %v", ids)
+	}
+	if req.URL != ts.URL+"/items/{kind}" || req.templateVars["kind"] != "books" {
+		t.Errorf("Expected Paginate to leave the url unchanged | but got %s with %v", req.URL, req.templateVars)
+	}
+}
+
+func TestPaginateOtherOrigin(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+			t.Errorf("Expected no credentials for another origin | but got %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":2}]`)
+	}))
+	defer other.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, ok := r.BasicAuth(); !ok || user != "user" {
+			t.Errorf("Expected basic auth of user | but got %s", user)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Link", "<"+other.URL+"/items?page=2>; rel=next")
+		fmt.Fprint(w, `[{"id":1}]`)
+	}))
+	defer ts.Close()
+
+	var ids []int
+	req := New().Get(ts.URL).SetBasicAuth("user", "pass").AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+	errs := req.Paginate(PageConfig{}, func(items []pageItem) error {
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("Expected ids 1 and 2 | but got %v", ids)
+	}
+	if req.BasicAuth.Username != "user" || len(req.Cookies) != 1 {
+		t.Errorf("Expected the credentials of the GoReq to be kept | but got %+v %v", req.BasicAuth, req.Cookies)
+	}
+}
@@ -0,0 +1,164 @@
+package goreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxPollBackoff is the longest time Poll waits before retrying a failed request.
+const maxPollBackoff = time.Minute
+
+// PollConfig is used to config Poll.
+type PollConfig struct {
+	// Cursor extracts the cursor of the next request from a response and its body, e.g. the id of the last event.
+	// If it is nil, the ETag header of the response is the cursor. An empty cursor keeps the previous one.
+	Cursor func(resp Response, body []byte) (string, error)
+	// Query parameter the cursor is sent in
+	QueryParam string
+	// Header the cursor is sent in. If neither QueryParam nor Header is set, the cursor is sent in If-None-Match.
+	Header string
+	// Time to wait between polls. Zero polls again at once, for long polling where the server holds the request until there is news.
+	Interval time.Duration
+}
+
+// cursor returns the cursor of resp.
+func (c *PollConfig) cursor(resp Response, body []byte) (string, error) {
+	if c.Cursor == nil {
+		return resp.Header.Get("ETag"), nil
+	}
+	return c.Cursor(resp, body)
+}
+
+// Poll sends the request again and again, calling handler with each response and its body, until the context set by WithContext is canceled.
+// The cursor extracted from a response by config.Cursor is sent in the query or a header of the next request,
+// so the server only responds with what is new. Responses with 304 Not Modified are skipped.
+// The requests are sent by a Clone of the GoReq, which is left unchanged.
+//
+// For example:
+//    ctx, cancel := context.WithCancel(context.Background())
+//    defer cancel()
+//    errs := goreq.New().
+//        Get("http://example.com/events").
+//        WithContext(ctx).
+//        Retry(5, 1, []int{502, 503}).
+//        Poll(goreq.PollConfig{
+//            Cursor: func(resp goreq.Response, body []byte) (string, error) {
+//                return resp.Header.Get("X-Next-Cursor"), nil
+//            },
+//            QueryParam: "since",
+//        }, func(resp goreq.Response, body []byte) error {
+//            fmt.Println(string(body))
+//            return nil
+//        })
+//
+// Failed requests are retried as configured by Retry: connection errors and the statuses of Retry, or all statuses >= 400 if they are nil,
+// are retried at most RetryCount times in a row. Poll waits RetryTimeout seconds before the first retry and doubles the wait for each further one, up to a minute.
+// Poll returns nil when ctx is canceled, the error of handler or config.Cursor unless it is ErrStop,
+// or the errors of a request which is not retried.
+func (gr *GoReq) Poll(config PollConfig, handler func(resp Response, body []byte) error) []error {
+	if len(gr.Errors) != 0 {
+		return gr.Errors
+	}
+	ctx := gr.context()
+	// the requests are sent by a clone, so the cursor is not left in gr, and retried by Poll with a backoff
+	gr = gr.Clone()
+	retry := gr.retry
+	gr.retry = &RetryConfig{}
+
+	var (
+		cursor   string
+		failures int
+	)
+	for {
+		if cursor != "" {
+			switch {
+			case config.QueryParam != "":
+				gr.QueryData.Set(config.QueryParam, cursor)
+			case config.Header != "":
+				gr.Header[config.Header] = cursor
+			default:
+				gr.Header["If-None-Match"] = cursor
+			}
+		}
+
+		resp, body, errs := gr.EndBytes()
+		if ctx.Err() != nil {
+			return nil
+		}
+		var err error
+		if errs != nil {
+			if _, ok := errs[len(errs)-1].(*url.Error); !ok {
+				return errs
+			}
+			err = errs[len(errs)-1]
+			gr.Errors = gr.Errors[:0]
+		} else if resp.StatusCode >= 400 {
+			err = fmt.Errorf("goreq: poll responded %s", resp.Status)
+			if !retryOnStatus(retry, resp.StatusCode) {
+				gr.Errors = append(gr.Errors, err)
+				return gr.Errors
+			}
+		}
+
+		wait := config.Interval
+		if err != nil {
+			failures++
+			if failures > retry.RetryCount {
+				gr.Errors = append(gr.Errors, err)
+				return gr.Errors
+			}
+			wait = pollBackoff(retry, failures)
+		} else {
+			failures = 0
+			if resp.StatusCode != http.StatusNotModified {
+				err := handler(resp, body)
+				if err == nil {
+					var next string
+					if next, err = config.cursor(resp, body); next != "" {
+						cursor = next
+					}
+				}
+				if err == ErrStop {
+					return nil
+				}
+				if err != nil {
+					gr.Errors = append(gr.Errors, err)
+					return gr.Errors
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryOnStatus reports whether a response with status is retried by retry.
+func retryOnStatus(retry *RetryConfig, status int) bool {
+	if retry.RetryOnHTTPStatus == nil {
+		return status >= 400
+	}
+	for _, s := range retry.RetryOnHTTPStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// pollBackoff returns the time to wait before retrying after failures failed requests in a row.
+func pollBackoff(retry *RetryConfig, failures int) time.Duration {
+	wait := time.Duration(retry.RetryTimeout) * time.Second
+	for i := 1; i < failures && wait < maxPollBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxPollBackoff {
+		wait = maxPollBackoff
+	}
+	return wait
+}
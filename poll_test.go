@@ -0,0 +1,157 @@
+package goreq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollCursor(t *testing.T) {
+	var since []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		since = append(since, r.URL.Query().Get("since"))
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("Expected query limit=2 | but got %s", r.URL.RawQuery)
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("since"))
+		fmt.Fprintf(w, `{"events":[%d,%d],"next":%d}`, n+1, n+2, n+2)
+	}))
+	defer ts.Close()
+
+	var events []int
+	req := New().Get(ts.URL).Query("limit=2")
+	errs := req.Poll(PollConfig{
+		Cursor: func(resp Response, body []byte) (string, error) {
+			var page struct{ Next int }
+			err := json.Unmarshal(body, &page)
+			return strconv.Itoa(page.Next), err
+		},
+		QueryParam: "since",
+	}, func(resp Response, body []byte) error {
+		var page struct{ Events []int }
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		events = append(events, page.Events...)
+		if len(events) == 6 {
+			return ErrStop
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(events, []int{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("Expected events 1 to 6 | but got %v", events)
+	}
+	if !reflect.DeepEqual(since, []string{"", "2", "4"}) {
+		t.Errorf("Expected cursors \"\", 2, 4 | but got %q", since)
+	}
+	if req.QueryData.Encode() != "limit=2" {
+		t.Errorf("Expected Poll to leave the query unchanged | but got %s", req.QueryData.Encode())
+	}
+}
+
+func TestPollETag(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		version := "v1"
+		if n >= 3 {
+			version = "v2"
+		}
+		if r.Header.Get("If-None-Match") == `"`+version+`"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"`+version+`"`)
+		fmt.Fprint(w, version)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var bodies []string
+	req := New().Get(ts.URL).WithContext(ctx)
+	errs := req.Poll(PollConfig{Interval: 10 * time.Millisecond}, func(resp Response, body []byte) error {
+		bodies = append(bodies, string(body))
+		if len(bodies) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !reflect.DeepEqual(bodies, []string{"v1", "v2"}) {
+		t.Errorf("Expected bodies v1, v2 | but got %q", bodies)
+	}
+	if atomic.LoadInt32(&requests) != 3 {
+		t.Errorf("Expected 3 requests | but got %d", requests)
+	}
+	if _, ok := req.Header["If-None-Match"]; ok {
+		t.Errorf("Expected Poll to leave the headers unchanged | but got If-None-Match %s", req.Header["If-None-Match"])
+	}
+}
+
+func TestPollRetry(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch {
+		case r.URL.Path == "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer ts.Close()
+
+	// the first request fails and is retried after a second
+	start := time.Now()
+	errs := New().Get(ts.URL).Retry(1, 1, []int{503}).Poll(PollConfig{}, func(resp Response, body []byte) error {
+		if string(body) != "ok" {
+			t.Errorf("Expected body ok | but got %s", body)
+		}
+		return ErrStop
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 3*time.Second {
+		t.Errorf("Expected to retry after a second | but took %s", elapsed)
+	}
+
+	// a status which is not retried, and too many failures
+	for _, path := range []string{"/missing", "/down"} {
+		atomic.StoreInt32(&requests, 0)
+		errs = New().Get(ts.URL+path).Retry(2, 0, []int{503}).Poll(PollConfig{}, func(resp Response, body []byte) error {
+			t.Errorf("Unexpected response of %s", path)
+			return nil
+		})
+		if errs == nil {
+			t.Errorf("Expected an error for %s", path)
+		}
+	}
+	if atomic.LoadInt32(&requests) != 3 {
+		t.Errorf("Expected 3 requests | but got %d", requests)
+	}
+
+	if wait := pollBackoff(&RetryConfig{RetryTimeout: 5}, 4); wait != 40*time.Second {
+		t.Errorf("Expected a backoff of 40s | but got %s", wait)
+	}
+	if wait := pollBackoff(&RetryConfig{RetryTimeout: 5}, 10); wait != maxPollBackoff {
+		t.Errorf("Expected a backoff of %s | but got %s", maxPollBackoff, wait)
+	}
+}
@@ -0,0 +1,84 @@
+package goreq
+
+import (
+	"net/http"
+	"time"
+)
+
+// TransportConfig is used to config the connection pool of a Pool.
+// Zero values mean the defaults of http.Transport.
+type TransportConfig struct {
+	// Max idle (keep-alive) connections across all hosts
+	MaxIdleConns int
+	// Max idle (keep-alive) connections to keep per host. http.Transport keeps 2 if it is zero.
+	MaxIdleConnsPerHost int
+	// Max connections per host, including connections in the dialing, active, and idle states
+	MaxConnsPerHost int
+	// How long an idle connection stays in the pool
+	IdleConnTimeout time.Duration
+	// Disable HTTP keep-alives and use a connection for a single request only
+	DisableKeepAlives bool
+}
+
+// Pool is a tuned connection pool which can be shared by many GoReq instances.
+// A Pool is safe for concurrent use and is never modified by GoReq.
+// Timeout, ConnectTimeout and Deadline apply to each request and keep sharing the connections of the Pool,
+// but if a GoReq using a Pool changes a setting of the connections, that is Proxy, Socks5, TLSClientConfig,
+// TLSHandshakeTimeout, ResponseHeaderTimeout or IdleConnTimeout, it gets its own copy of the transport
+// and stops sharing the connections of the Pool.
+type Pool struct {
+	transport *http.Transport
+}
+
+// NewPool returns a new Pool configured by config.
+//
+// For example:
+//    pool := goreq.NewPool(goreq.TransportConfig{
+//        MaxIdleConns:        100,
+//        MaxIdleConnsPerHost: 10,
+//        IdleConnTimeout:     90 * time.Second,
+//    })
+//
+//    goreq.New().SetPool(pool).Get("http://example.com").End()
+//    goreq.New().SetPool(pool).Get("http://example.com/other").End()
+//
+func NewPool(config TransportConfig) *Pool {
+	return &Pool{
+		transport: &http.Transport{
+			MaxIdleConns:        config.MaxIdleConns,
+			MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     config.MaxConnsPerHost,
+			IdleConnTimeout:     config.IdleConnTimeout,
+			DisableKeepAlives:   config.DisableKeepAlives,
+			DialContext:         dialContext(nil),
+			DisableCompression:  true,
+		},
+	}
+}
+
+// Transport returns the underlying transport of the Pool. It must not be modified.
+func (p *Pool) Transport() *http.Transport {
+	return p.transport
+}
+
+// CloseIdleConnections closes the idle connections in the Pool.
+func (p *Pool) CloseIdleConnections() {
+	p.transport.CloseIdleConnections()
+}
+
+// SetPool is used to share the connections of pool with other GoReq instances.
+// Transport settings made before SetPool are discarded. Timeout, ConnectTimeout and Deadline are kept.
+func (gr *GoReq) SetPool(pool *Pool) *GoReq {
+	gr.Transport = pool.transport
+	gr.sharedTransport = true
+	return gr
+}
+
+// ownTransport returns a transport which this GoReq can modify, copying a shared one first.
+func (gr *GoReq) ownTransport() *http.Transport {
+	if gr.sharedTransport {
+		gr.Transport = gr.Transport.Clone()
+		gr.sharedTransport = false
+	}
+	return gr.Transport
+}
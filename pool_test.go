@@ -0,0 +1,84 @@
+package goreq
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	ts, conns := newConnCountingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("hello world"))
+	}))
+
+	pool := NewPool(TransportConfig{MaxIdleConnsPerHost: 5, MaxConnsPerHost: 5})
+	defer pool.CloseIdleConnections()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, body, errs := New().SetPool(pool).Get(ts.URL).End()
+			if errs != nil || body != "hello world" {
+				t.Errorf("Unexpected response %q, errors: %v", body, errs)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(conns); n > 5 {
+		t.Errorf("Expected at most 5 connections | but got %d", n)
+	}
+}
+
+func TestPoolIsNotModified(t *testing.T) {
+	pool := NewPool(TransportConfig{MaxIdleConnsPerHost: 10})
+
+	gr := New().SetPool(pool)
+	if gr.Transport != pool.Transport() {
+		t.Fatal("Expected the transport of the pool")
+	}
+
+	gr.Proxy("http://127.0.0.1:9999").
+		TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+		ConnectTimeout(time.Second)
+	if gr.Transport == pool.Transport() {
+		t.Fatal("Expected a copy of the transport of the pool")
+	}
+	if gr.Transport.MaxIdleConnsPerHost != 10 {
+		t.Errorf("Expected the copy keeps MaxIdleConnsPerHost 10 | but got %d", gr.Transport.MaxIdleConnsPerHost)
+	}
+	// net/http may set TLSClientConfig itself to configure HTTP/2
+	p := pool.Transport()
+	if p.Proxy != nil || (p.TLSClientConfig != nil && p.TLSClientConfig.InsecureSkipVerify) {
+		t.Error("Expected the transport of the pool unchanged")
+	}
+}
+
+func TestPoolWithTimeouts(t *testing.T) {
+	ts, conns := newConnCountingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+
+	pool := NewPool(TransportConfig{MaxIdleConnsPerHost: 5})
+	defer pool.CloseIdleConnections()
+
+	for i := 0; i < 5; i++ {
+		gr := New().SetPool(pool).Timeout(time.Second).ConnectTimeout(time.Second).Deadline(time.Second)
+		_, body, errs := gr.Get(ts.URL).End()
+		if errs != nil || body != "hello world" {
+			t.Fatalf("Unexpected response %q, errors: %v", body, errs)
+		}
+		if gr.Transport != pool.Transport() {
+			t.Fatal("Expected timeouts to keep the transport of the pool")
+		}
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("Expected 1 shared connection | but got %d", n)
+	}
+}
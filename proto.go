@@ -0,0 +1,27 @@
+package goreq
+
+// SendProto sends msg, a proto.Message, as the body. It is encoded by the codec of the Content-Type,
+// which is application/x-protobuf unless another one is set. The codecs of protobuf are registered by importing
+// github.com/smallnest/goreq/codec/protobuf, which also sends the JSON mapping of msg with a JSON content type:
+//      import _ "github.com/smallnest/goreq/codec/protobuf"
+//
+//      goreq.New().
+//        Post("/v1/users").
+//        SendProto(&pb.User{Name: "nemo"}).
+//        BindBody(&reply).
+//        End()
+//
+//      goreq.New().
+//        Post("/v1/users").
+//        ContentType("json").
+//        SendProto(&pb.User{Name: "nemo"}).
+//        End()
+//
+// Like SendJSON, SendProto replaces a previous value and can not be combined with SendStruct or SendMapString.
+func (gr *GoReq) SendProto(msg interface{}) *GoReq {
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/x-protobuf"
+	}
+	gr.bodyValue = &bodyValue{value: msg}
+	return gr
+}
@@ -0,0 +1,213 @@
+package goreq
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSSERetry is the time to wait before reconnecting to an event stream, unless the server sets another one.
+var DefaultSSERetry = 3 * time.Second
+
+// maxEventLine is the maximum length of a line in an event stream.
+const maxEventLine = 1 << 20
+
+// Event is an event received from a Server-Sent Events stream.
+type Event struct {
+	// ID is the last event ID of the stream.
+	ID string
+	// Type is the event field, "message" if the event has none.
+	Type string
+	// Data is the data of the event. The lines of multiple data fields are joined with "\n".
+	Data string
+}
+
+// SSE sends the request and reads the response as a Server-Sent Events stream (text/event-stream), calling handler with each event.
+// When the connection is closed or lost, SSE reconnects after the retry delay set by the server, or DefaultSSERetry,
+// sending the ID of the last event in the Last-Event-ID header.
+//
+// For example:
+//    ctx, cancel := context.WithCancel(context.Background())
+//    defer cancel()
+//    errs := goreq.New().
+//        Get("http://example.com/events").
+//        WithContext(ctx).
+//        SSE(func(e goreq.Event) error {
+//            fmt.Println(e.Type, e.Data)
+//            return nil
+//        })
+//
+// SSE returns when ctx is canceled, handler returns an error, which is returned unless it is ErrStop,
+// the server responds with 204 No Content, or the response is not an event stream.
+// Don't set a Timeout or Deadline unless the stream should be reconnected when they expire.
+func (gr *GoReq) SSE(handler func(e Event) error) []error {
+	if len(gr.Errors) != 0 {
+		return gr.Errors
+	}
+	ctx := gr.context()
+	gr.Header["Accept"] = "text/event-stream"
+	gr.Header["Cache-Control"] = "no-cache"
+	stream := &eventStream{retry: DefaultSSERetry}
+
+	for {
+		if stream.lastEventID != "" {
+			gr.Header["Last-Event-ID"] = stream.lastEventID
+		}
+		resp, errs := gr.send()
+		if errs != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			// reconnect if the connection failed
+			if _, ok := errs[len(errs)-1].(*url.Error); !ok {
+				return errs
+			}
+			gr.Errors = gr.Errors[:0]
+		} else {
+			if err := checkEventStream(resp); err != nil {
+				resp.Body.Close()
+				if err == ErrStop {
+					return nil
+				}
+				gr.Errors = append(gr.Errors, err)
+				return gr.Errors
+			}
+			err := stream.read(resp.Body, handler)
+			resp.Body.Close()
+			if err == ErrStop {
+				return nil
+			}
+			if err != nil {
+				gr.Errors = append(gr.Errors, err)
+				return gr.Errors
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(stream.retry):
+		}
+	}
+}
+
+// checkEventStream checks the response of an event stream. It returns ErrStop for 204 No Content.
+func checkEventStream(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNoContent {
+		return ErrStop
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("goreq: event stream responded %s", resp.Status)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		return fmt.Errorf("goreq: event stream responded Content-Type %q", resp.Header.Get("Content-Type"))
+	}
+	return nil
+}
+
+// eventStream parses an event stream as defined in https://html.spec.whatwg.org/multipage/server-sent-events.html.
+// The last event ID and the retry delay are kept across connections.
+type eventStream struct {
+	lastEventID string
+	retry       time.Duration
+}
+
+// read calls handler with the events in r until the end of r. It returns the error of handler or a line which is too long,
+// and nil if r ends or can not be read, so the stream can be reconnected.
+func (s *eventStream) read(r io.Reader, handler func(e Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxEventLine)
+	scanner.Split(scanEventLines)
+
+	var (
+		eventType string
+		data      bytes.Buffer
+		first     = true
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		// an empty line dispatches the event
+		if line == "" {
+			if data.Len() > 0 {
+				e := Event{ID: s.lastEventID, Type: eventType, Data: strings.TrimSuffix(data.String(), "\n")}
+				if e.Type == "" {
+					e.Type = "message"
+				}
+				if err := handler(e); err != nil {
+					return err
+				}
+			}
+			eventType = ""
+			data.Reset()
+			continue
+		}
+		if line[0] == ':' { // comment
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if strings.IndexByte(value, 0) < 0 {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	// an incomplete event at the end of the stream is discarded
+	if err := scanner.Err(); err == bufio.ErrTooLong {
+		return err
+	}
+	return nil
+}
+
+// scanEventLines is a bufio.SplitFunc for lines ending with CRLF, LF or CR.
+func scanEventLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// wait for a LF after the CR
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
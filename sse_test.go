@@ -0,0 +1,154 @@
+package goreq
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventStreamParse(t *testing.T) {
+	const input = "\ufeff: comment\r\n" +
+		"data: first\r\n\r\n" +
+		"event: update\rdata:second\rdata:  line\r\rid: 7\n" +
+		"data\n\n" +
+		"id: a\x00b\ndata: {\"x\":1}\nretry: 1500\nunknown: field\n\n" +
+		"retry: soon\n" +
+		"event: ignored\n\n" +
+		"data: incomplete"
+
+	stream := &eventStream{retry: DefaultSSERetry}
+	var events []Event
+	err := stream.read(strings.NewReader(input), func(e Event) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{
+		{ID: "", Type: "message", Data: "first"},
+		{ID: "", Type: "update", Data: "second\n line"},
+		{ID: "7", Type: "message", Data: ""},
+		{ID: "7", Type: "message", Data: `{"x":1}`},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %+v | but got %+v", expected, events)
+	}
+	if stream.lastEventID != "7" || stream.retry != 1500*time.Millisecond {
+		t.Errorf("Expected last event id 7 and retry 1.5s | but got %q and %s", stream.lastEventID, stream.retry)
+	}
+}
+
+func TestSSEReconnect(t *testing.T) {
+	connections := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections++
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected Header Accept -> text/event-stream | but got %s", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connections {
+		case 1:
+			if r.Header.Get("Last-Event-ID") != "" {
+				t.Errorf("Expected no Last-Event-ID | but got %s", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "retry: 10\n\nid: 1\ndata: one\n\nid: 2\ndata: two\n\n")
+		case 2:
+			if r.Header.Get("Last-Event-ID") != "2" {
+				t.Errorf("Expected Last-Event-ID 2 | but got %s", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "id: 3\nevent: done\ndata: three\n\n")
+		default:
+			// no more events
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	var events []Event
+	start := time.Now()
+	errs := New().Get(ts.URL).SSE(func(e Event) error {
+		events = append(events, e)
+		return nil
+	})
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected to reconnect after the retry of the server | but took %s", time.Since(start))
+	}
+	expected := []Event{{"1", "message", "one"}, {"2", "message", "two"}, {"3", "done", "three"}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %+v | but got %+v", expected, events)
+	}
+	if connections != 3 {
+		t.Errorf("Expected 3 connections | but got %d", connections)
+	}
+}
+
+func TestSSECancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []error)
+	go func() {
+		done <- New().Get(ts.URL).WithContext(ctx).SSE(func(e Event) error {
+			if e.Data != "hello" {
+				t.Errorf("Expected data hello | but got %s", e.Data)
+			}
+			cancel()
+			return nil
+		})
+	}()
+
+	select {
+	case errs := <-done:
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SSE to return when the context is canceled")
+	}
+}
+
+func TestSSEErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/json" {
+			w.Header().Set("Content-Type", "application/json")
+			return
+		}
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		fmt.Fprint(w, "data: 1\n\ndata: 2\n\n")
+	}))
+	defer ts.Close()
+
+	for _, path := range []string{"/json", "/error"} {
+		if errs := New().Get(ts.URL + path).SSE(func(e Event) error { return nil }); errs == nil {
+			t.Errorf("Expected an error for %s", path)
+		}
+	}
+
+	count := 0
+	errs := New().Get(ts.URL).SSE(func(e Event) error {
+		count++
+		return ErrStop
+	})
+	if errs != nil || count != 1 {
+		t.Errorf("Expected to stop after 1 event | but got %d, %v", count, errs)
+	}
+}
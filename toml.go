@@ -0,0 +1,24 @@
+package goreq
+
+// SendTOML sends the TOML encoding of v, which must be a struct or a map, as the body,
+// with the Content-Type application/toml unless another one is set.
+// The TOML codec is registered by importing github.com/smallnest/goreq/codec/toml:
+//      import _ "github.com/smallnest/goreq/codec/toml"
+//
+//      type Config struct {
+//        Name     string `toml:"name"`
+//        Replicas int    `toml:"replicas"`
+//      }
+//      goreq.New().
+//        Put("/configs/web").
+//        SendTOML(Config{Name: "web", Replicas: 3}).
+//        End()
+//
+// Like SendJSON, SendTOML replaces a previous value and can not be combined with SendStruct or SendMapString.
+func (gr *GoReq) SendTOML(v interface{}) *GoReq {
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/toml"
+	}
+	gr.bodyValue = &bodyValue{value: v}
+	return gr
+}
@@ -0,0 +1,390 @@
+package goreq
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// URITemplate is a parsed URI template as defined in RFC 6570, supporting all expressions up to level 4.
+type URITemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+// TemplatePair is a name and value of an associative array in URI template variables.
+// Use a []TemplatePair instead of a map if the order of names matters.
+type TemplatePair struct {
+	Name, Value string
+}
+
+// templatePart is a literal or an expression of a URI template.
+type templatePart struct {
+	literal  string
+	op       *templateOperator
+	varspecs []templateVarspec
+}
+
+type templateVarspec struct {
+	name      string
+	maxLength int
+	explode   bool
+}
+
+// templateOperator defines how an expression is expanded, see Appendix A of RFC 6570.
+type templateOperator struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+var templateOperators = map[byte]*templateOperator{
+	'+': {first: "", sep: ",", allowReserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+	'#': {first: "#", sep: ",", allowReserved: true},
+}
+
+var simpleOperator = &templateOperator{first: "", sep: ","}
+
+// ParseURITemplate parses a URI template.
+func ParseURITemplate(template string) (*URITemplate, error) {
+	t := &URITemplate{raw: template}
+	for len(template) > 0 {
+		i := strings.IndexAny(template, "{}")
+		if i < 0 {
+			t.parts = append(t.parts, templatePart{literal: template})
+			break
+		}
+		if template[i] == '}' {
+			return nil, fmt.Errorf("malformed uri template %q: unexpected }", t.raw)
+		}
+		if i > 0 {
+			t.parts = append(t.parts, templatePart{literal: template[:i]})
+		}
+		j := strings.IndexByte(template[i:], '}')
+		if j < 0 {
+			return nil, fmt.Errorf("malformed uri template %q: missing }", t.raw)
+		}
+		part, err := parseExpression(template[i+1 : i+j])
+		if err != nil {
+			return nil, fmt.Errorf("malformed uri template %q: %v", t.raw, err)
+		}
+		t.parts = append(t.parts, part)
+		template = template[i+j+1:]
+	}
+	return t, nil
+}
+
+func parseExpression(expression string) (templatePart, error) {
+	part := templatePart{op: simpleOperator}
+	if expression == "" {
+		return part, fmt.Errorf("empty expression")
+	}
+	if op, ok := templateOperators[expression[0]]; ok {
+		part.op = op
+		expression = expression[1:]
+	} else if strings.IndexByte("=,!@|", expression[0]) >= 0 {
+		return part, fmt.Errorf("reserved operator %q", expression[0])
+	}
+
+	for _, spec := range strings.Split(expression, ",") {
+		varspec := templateVarspec{name: spec}
+		if strings.HasSuffix(spec, "*") {
+			varspec.name = spec[:len(spec)-1]
+			varspec.explode = true
+		} else if i := strings.IndexByte(spec, ':'); i >= 0 {
+			varspec.name = spec[:i]
+			n, err := strconv.Atoi(spec[i+1:])
+			if err != nil || n < 1 || n > 9999 || len(spec)-i-1 > 4 {
+				return part, fmt.Errorf("invalid prefix modifier in %q", spec)
+			}
+			varspec.maxLength = n
+		}
+		if !validVarname(varspec.name) {
+			return part, fmt.Errorf("invalid variable name %q", varspec.name)
+		}
+		part.varspecs = append(part.varspecs, varspec)
+	}
+	return part, nil
+}
+
+// validVarname reports whether name is a varname: varchar *( ["."] varchar ).
+func validVarname(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		case c == '.':
+			if name[i-1] == '.' {
+				return false
+			}
+		case c == '%':
+			if i+2 >= len(name) || !isHex(name[i+1]) || !isHex(name[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the template.
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Names returns the names of variables in the template.
+func (t *URITemplate) Names() []string {
+	var names []string
+	for _, part := range t.parts {
+		for _, varspec := range part.varspecs {
+			names = append(names, varspec.name)
+		}
+	}
+	return names
+}
+
+// Expand expands the template with vars.
+// A value can be a string, a list ([]string or any other slice) or an associative array (map[string]string,
+// map[string]interface{} whose names are expanded in sorted order, or []TemplatePair).
+// Numbers, bools and other values are formatted with fmt.Sprint. Nil values, empty lists and empty arrays are undefined.
+func (t *URITemplate) Expand(vars map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	for _, part := range t.parts {
+		if part.op == nil {
+			buf.WriteString(encodeLiteral(part.literal))
+			continue
+		}
+		if err := part.expand(&buf, vars); err != nil {
+			return "", fmt.Errorf("failed to expand uri template %q: %v", t.raw, err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// ExpandURITemplate parses and expands a URI template with vars. See URITemplate.Expand.
+//
+// For example:
+//    u, err := goreq.ExpandURITemplate("http://example.com/search{?q,page}",
+//        map[string]interface{}{"q": "go req", "page": 2})
+//
+// returns "http://example.com/search?q=go%20req&page=2".
+func ExpandURITemplate(template string, vars map[string]interface{}) (string, error) {
+	t, err := ParseURITemplate(template)
+	if err != nil {
+		return "", err
+	}
+	return t.Expand(vars)
+}
+
+func (part templatePart) expand(buf *bytes.Buffer, vars map[string]interface{}) error {
+	op := part.op
+	first := true
+	for _, varspec := range part.varspecs {
+		value, err := templateValue(vars[varspec.name])
+		if err != nil {
+			return fmt.Errorf("variable %s: %v", varspec.name, err)
+		}
+		if value == nil {
+			continue
+		}
+		if first {
+			buf.WriteString(op.first)
+			first = false
+		} else {
+			buf.WriteString(op.sep)
+		}
+
+		switch v := value.(type) {
+		case string:
+			if op.named {
+				buf.WriteString(varspec.name)
+				if v == "" {
+					buf.WriteString(op.ifEmpty)
+					continue
+				}
+				buf.WriteByte('=')
+			}
+			if varspec.maxLength > 0 && utf8.RuneCountInString(v) > varspec.maxLength {
+				v = string([]rune(v)[:varspec.maxLength])
+			}
+			buf.WriteString(encodeTemplateValue(v, op.allowReserved))
+		case []string:
+			if varspec.maxLength > 0 {
+				return fmt.Errorf("prefix modifier is not applicable to variable %s", varspec.name)
+			}
+			for i, item := range v {
+				if i > 0 {
+					if varspec.explode {
+						buf.WriteString(op.sep)
+					} else {
+						buf.WriteByte(',')
+					}
+				}
+				if op.named && (varspec.explode || i == 0) {
+					buf.WriteString(varspec.name)
+					if varspec.explode && item == "" {
+						buf.WriteString(op.ifEmpty)
+						continue
+					}
+					buf.WriteByte('=')
+				}
+				buf.WriteString(encodeTemplateValue(item, op.allowReserved))
+			}
+		case []TemplatePair:
+			if varspec.maxLength > 0 {
+				return fmt.Errorf("prefix modifier is not applicable to variable %s", varspec.name)
+			}
+			if op.named && !varspec.explode {
+				buf.WriteString(varspec.name)
+				buf.WriteByte('=')
+			}
+			for i, pair := range v {
+				if i > 0 {
+					if varspec.explode {
+						buf.WriteString(op.sep)
+					} else {
+						buf.WriteByte(',')
+					}
+				}
+				buf.WriteString(encodeTemplateValue(pair.Name, op.allowReserved))
+				if varspec.explode {
+					if op.named && pair.Value == "" {
+						buf.WriteString(op.ifEmpty)
+						continue
+					}
+					buf.WriteByte('=')
+				} else {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(encodeTemplateValue(pair.Value, op.allowReserved))
+			}
+		}
+	}
+	return nil
+}
+
+// templateValue converts a variable to a string, a []string or a []TemplatePair. It returns nil for undefined variables.
+func templateValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	case []string:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return v, nil
+	case []TemplatePair:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return v, nil
+	case map[string]string:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		pairs := make([]TemplatePair, len(names))
+		for i, name := range names {
+			pairs[i] = TemplatePair{name, v[name]}
+		}
+		return pairs, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return templateValue(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return nil, nil
+		}
+		list := make([]string, rv.Len())
+		for i := range list {
+			list[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return list, nil
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return nil, nil
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map type %T", value)
+		}
+		pairs := make([]TemplatePair, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			pairs = append(pairs, TemplatePair{key.String(), fmt.Sprint(rv.MapIndex(key).Interface())})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+		return pairs, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+const hexDigits = "0123456789ABCDEF"
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+// encodeTemplateValue pct-encodes s, keeping unreserved characters, and reserved characters
+// and pct-encoded triplets if allowReserved is true.
+func encodeTemplateValue(s string, allowReserved bool) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			buf.WriteByte(c)
+		case allowReserved && isReserved(c):
+			buf.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			buf.WriteString(s[i : i+3])
+			i += 2
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&15])
+		}
+	}
+	return buf.String()
+}
+
+// encodeLiteral pct-encodes characters which are not allowed in a URI.
+func encodeLiteral(s string) string {
+	return encodeTemplateValue(s, true)
+}
@@ -0,0 +1,326 @@
+package goreq
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encodeValues encodes a struct (or a pointer to a struct, or a map with string keys) into url.Values
+// using the field names and options in tag, which falls back to the json tag.
+//
+// Supported options are:
+//
+//    omitempty          omit the field if it has an empty value
+//    comma              encode a slice as one comma separated value: a=1,2
+//    brackets           encode a slice with brackets: a[]=1&a[]=2 (slices are repeated by default: a=1&a=2)
+//    dot                encode fields of a nested struct with dot notation: user.name=x (brackets by default: user[name]=x)
+//    int                encode a bool as 1 or 0
+//    unix, unixmilli    encode a time.Time as a unix timestamp in seconds or milliseconds
+//
+// A time.Time is encoded as RFC 3339 unless the field has a layout tag, such as `layout:"2006-01-02"`.
+// Nil pointers are always omitted, and types implementing encoding.TextMarshaler are encoded with MarshalText.
+// A nil v is encoded into empty values.
+func encodeValues(v interface{}, tag string) (url.Values, error) {
+	values := url.Values{}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return values, nil
+	}
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+	e := &valuesEncoder{tag: tag, values: values}
+	switch rv.Kind() {
+	case reflect.Struct:
+		if err := e.encodeStruct("", rv, fieldOptions{}); err != nil {
+			return nil, err
+		}
+	case reflect.Map:
+		if err := e.encodeMap("", rv, fieldOptions{}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("goreq: can not encode %s into values", rv.Type())
+	}
+	return values, nil
+}
+
+type valuesEncoder struct {
+	tag    string
+	values url.Values
+}
+
+// fieldOptions are the options of a struct field.
+type fieldOptions struct {
+	omitempty bool
+	comma     bool
+	brackets  bool
+	dot       bool
+	int       bool
+	unix      bool
+	unixmilli bool
+	layout    string
+}
+
+// fieldName returns the name and options of a struct field from the tag, or the json tag.
+func (e *valuesEncoder) fieldName(field reflect.StructField) (string, fieldOptions, bool) {
+	tag, ok := field.Tag.Lookup(e.tag)
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", fieldOptions{}, false
+	}
+
+	parts := strings.Split(tag, ",")
+	opts := fieldOptions{layout: field.Tag.Get("layout")}
+	for _, o := range parts[1:] {
+		switch o {
+		case "omitempty":
+			opts.omitempty = true
+		case "comma":
+			opts.comma = true
+		case "brackets":
+			opts.brackets = true
+		case "dot":
+			opts.dot = true
+		case "int":
+			opts.int = true
+		case "unix":
+			opts.unix = true
+		case "unixmilli":
+			opts.unixmilli = true
+		}
+	}
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name, opts, true
+}
+
+// join returns the name of a nested field.
+func join(prefix, name string, opts fieldOptions) string {
+	if prefix == "" {
+		return name
+	}
+	if opts.dot {
+		return prefix + "." + name
+	}
+	return prefix + "[" + name + "]"
+}
+
+func (e *valuesEncoder) encodeStruct(prefix string, v reflect.Value, parent fieldOptions) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // unexported
+			continue
+		}
+		name, opts, ok := e.fieldName(field)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+
+		// fields of an embedded struct without name are promoted
+		if field.Anonymous && name == field.Name {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && !isScalarStruct(fv.Type()) {
+				if err := e.encodeStruct(prefix, fv, parent); err != nil {
+					return err
+				}
+				continue
+			}
+			if field.PkgPath != "" {
+				continue
+			}
+		}
+
+		if opts.omitempty && isEmptyValue(fv) {
+			continue
+		}
+		opts.dot = opts.dot || parent.dot
+		if err := e.encode(join(prefix, name, parent), fv, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *valuesEncoder) encodeMap(prefix string, v reflect.Value, opts fieldOptions) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("goreq: can not encode map with %s keys", v.Type().Key())
+	}
+	for _, key := range v.MapKeys() {
+		if err := e.encode(join(prefix, key.String(), opts), v.MapIndex(key), opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *valuesEncoder) encode(name string, v reflect.Value, opts fieldOptions) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if isScalarStruct(v.Type()) || v.Kind() != reflect.Struct && v.Kind() != reflect.Map && v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		s, err := scalarString(v, opts)
+		if err != nil {
+			return fmt.Errorf("goreq: can not encode %s: %v", name, err)
+		}
+		e.values.Add(name, s)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(name, v, opts)
+	case reflect.Map:
+		return e.encodeMap(name, v, opts)
+	}
+
+	// slices and arrays
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		// []byte is a string
+		e.values.Add(name, string(v.Bytes()))
+		return nil
+	}
+	if opts.comma {
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, err := scalarString(indirect(v.Index(i)), opts)
+			if err != nil {
+				return fmt.Errorf("goreq: can not encode %s: %v", name, err)
+			}
+			items = append(items, s)
+		}
+		e.values.Add(name, strings.Join(items, ","))
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := indirect(v.Index(i))
+		if item.Kind() == reflect.Struct && !isScalarStruct(item.Type()) || item.Kind() == reflect.Map {
+			// nested values are indexed: items[0][name] or items.0.name
+			if err := e.encode(join(name, strconv.Itoa(i), opts), item, opts); err != nil {
+				return err
+			}
+			continue
+		}
+		itemName := name
+		if opts.brackets {
+			itemName = name + "[]"
+		}
+		if err := e.encode(itemName, item, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indirect dereferences pointers and interfaces.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// isScalarStruct reports whether a struct type is encoded as a single value.
+func isScalarStruct(t reflect.Type) bool {
+	return t == timeType || t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+// scalarString formats a single value.
+func scalarString(v reflect.Value, opts fieldOptions) (string, error) {
+	if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "", nil
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		switch {
+		case opts.unix:
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case opts.unixmilli:
+			return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10), nil
+		case opts.layout != "":
+			return t.Format(opts.layout), nil
+		}
+		return t.Format(time.RFC3339), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		if opts.int {
+			if v.Bool() {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// isEmptyValue reports whether v is empty for omitempty, like encoding/json, plus zero time.Time.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package goreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeValues(t *testing.T) {
+	type Address struct {
+		City string `url:"city"`
+		Zip  string `url:"zip,omitempty"`
+	}
+	type Item struct {
+		ID int `url:"id"`
+	}
+	type Paging struct {
+		Page    int `url:"page"`
+		PerPage int `url:"per_page,omitempty"`
+	}
+	type Query struct {
+		Paging
+		Name     string            `url:"name"`
+		Age      int               `url:"age"`
+		Score    float64           `url:"score"`
+		Active   bool              `url:"active"`
+		Admin    bool              `url:"admin,int"`
+		Since    time.Time         `url:"since" layout:"2006-01-02"`
+		Until    time.Time         `url:"until"`
+		Stamp    time.Time         `url:"stamp,unix"`
+		Zero     time.Time         `url:"zero,omitempty"`
+		Tags     []string          `url:"tags"`
+		IDs      []int             `url:"ids,comma"`
+		Labels   []string          `url:"labels,brackets"`
+		Home     Address           `url:"home"`
+		Work     Address           `url:"work,dot"`
+		Items    []Item            `url:"items"`
+		Extra    map[string]string `url:"extra"`
+		Nickname *string           `url:"nickname"`
+		Limit    *int              `url:"limit"`
+		Skipped  string            `url:"-"`
+		JSONName string            `json:"json_name"`
+		Empty    string            `url:"empty,omitempty"`
+		NoTag    string
+		private  string
+	}
+
+	limit := 0
+	q := Query{
+		Paging:   Paging{Page: 2},
+		Name:     "nemo",
+		Age:      3,
+		Score:    9.5,
+		Active:   true,
+		Admin:    true,
+		Since:    time.Date(2018, 7, 27, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2018, 7, 28, 10, 30, 0, 0, time.UTC),
+		Stamp:    time.Unix(1532649600, 0),
+		Tags:     []string{"a", "b"},
+		IDs:      []int{1, 2, 3},
+		Labels:   []string{"x", "y"},
+		Home:     Address{City: "Sydney"},
+		Work:     Address{City: "Tokyo", Zip: "100"},
+		Items:    []Item{{1}, {2}},
+		Extra:    map[string]string{"k": "v"},
+		Limit:    &limit,
+		Skipped:  "skipped",
+		JSONName: "json",
+		NoTag:    "notag",
+		private:  "private",
+	}
+	values, err := encodeValues(&q, "url")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := url.Values{
+		"page":         {"2"},
+		"name":         {"nemo"},
+		"age":          {"3"},
+		"score":        {"9.5"},
+		"active":       {"true"},
+		"admin":        {"1"},
+		"since":        {"2018-07-27"},
+		"until":        {"2018-07-28T10:30:00Z"},
+		"stamp":        {"1532649600"},
+		"tags":         {"a", "b"},
+		"ids":          {"1,2,3"},
+		"labels[]":     {"x", "y"},
+		"home[city]":   {"Sydney"},
+		"work.city":    {"Tokyo"},
+		"work.zip":     {"100"},
+		"items[0][id]": {"1"},
+		"items[1][id]": {"2"},
+		"extra[k]":     {"v"},
+		"limit":        {"0"},
+		"json_name":    {"json"},
+		"NoTag":        {"notag"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v | but got %v", expected, values)
+	}
+}
+
+func TestEncodeValuesNil(t *testing.T) {
+	var profile *struct{ Name string }
+	for _, v := range []interface{}{nil, profile} {
+		values, err := encodeValues(v, "form")
+		if err != nil || len(values) != 0 {
+			t.Errorf("Expected empty values for %#v | but got %v, %v", v, values, err)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	if _, _, errs := New().Post(ts.URL).SendForm(nil).End(); errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+}
+
+func TestQueryStruct(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query()
+		checkQuery(t, v, "page", "2")
+		checkQuery(t, v, "active", "true")
+		checkQuery(t, v, "filter[state]", "open")
+	}))
+	defer ts.Close()
+
+	type Filter struct {
+		State string `url:"state"`
+	}
+	q := struct {
+		Page   int    `url:"page"`
+		Active bool   `url:"active"`
+		Filter Filter `url:"filter"`
+	}{2, true, Filter{"open"}}
+
+	_, _, errs := New().Get(ts.URL).Query(q).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+	_, _, errs = New().Get(ts.URL).Query(&q).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+}
+
+func TestSendForm(t *testing.T) {
+	type Address struct {
+		City string `form:"city"`
+	}
+	type Profile struct {
+		Name    string    `form:"name"`
+		Age     int       `form:"age"`
+		Admin   bool      `form:"admin"`
+		Tags    []string  `form:"tags"`
+		Address Address   `form:"address"`
+		Friends []Address `json:"friends"`
+	}
+	profile := Profile{
+		Name:    "nemo",
+		Age:     3,
+		Admin:   true,
+		Tags:    []string{"a", "b"},
+		Address: Address{"Sydney"},
+		Friends: []Address{{"Tokyo"}},
+	}
+	expected := url.Values{
+		"name":             {"nemo"},
+		"age":              {"3"},
+		"admin":            {"true"},
+		"tags":             {"a", "b"},
+		"address[city]":    {"Sydney"},
+		"friends[0][city]": {"Tokyo"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/multipart" {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, _, err := r.FormFile("file"); err != nil {
+				t.Error(err)
+			}
+			if !reflect.DeepEqual(url.Values(r.MultipartForm.Value), expected) {
+				t.Errorf("Expected multipart fields %v | but got %v", expected, r.MultipartForm.Value)
+			}
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("Expected form content type | but got %s", r.Header.Get("Content-Type"))
+		}
+		r.ParseForm()
+		if !reflect.DeepEqual(r.PostForm, expected) {
+			t.Errorf("Expected form %v | but got %v", expected, r.PostForm)
+		}
+	}))
+	defer ts.Close()
+
+	_, _, errs := New().Post(ts.URL).SendForm(profile).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+
+	_, _, errs = New().Post(ts.URL + "/multipart").SendForm(&profile).SendFile("file", "./LICENSE").End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+
+	// bools, numbers and nested objects of SendMapString are kept too
+	_, _, errs = New().Post(ts.URL).
+		ContentType("form").
+		SendMapString(`{"name":"nemo","age":3,"admin":true,"tags":["a","b"],"address":{"city":"Sydney"},"friends":[{"city":"Tokyo"}]}`).
+		End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+}
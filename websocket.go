@@ -0,0 +1,68 @@
+package goreq
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHandshakeTimeout is the timeout of the opening handshake of WebSocket if no Timeout is set.
+const defaultHandshakeTimeout = 45 * time.Second
+
+// WebSocketHandshake is the opening handshake of a WebSocket connection (RFC 6455),
+// which is dialed by the package github.com/smallnest/goreq/websocket.
+type WebSocketHandshake struct {
+	// Request is the GET request of the handshake to a ws or wss url, with the headers, query, cookies and basic auth of the GoReq.
+	// Its context carries the ConnectTimeout for the DialContext of Transport.
+	Request *http.Request
+	// Jar is the cookie jar of the GoReq.
+	Jar http.CookieJar
+	// Transport has the proxy, Socks5 and TLS settings of the GoReq.
+	Transport *http.Transport
+	// Timeout limits the handshake. It is the Timeout or Deadline of the GoReq, 45 seconds by default.
+	Timeout time.Duration
+}
+
+// WebSocketHandshake returns the opening handshake of a WebSocket connection to the url of the GoReq.
+// The url can be http, https, ws or wss. Use the package github.com/smallnest/goreq/websocket to open the connection.
+func (gr *GoReq) WebSocketHandshake() (*WebSocketHandshake, []error) {
+	if len(gr.Errors) != 0 {
+		return nil, gr.Errors
+	}
+	reqURL, err := gr.requestURL()
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, gr.Errors
+	}
+	switch {
+	case strings.HasPrefix(reqURL, "http://"):
+		reqURL = "ws://" + strings.TrimPrefix(reqURL, "http://")
+	case strings.HasPrefix(reqURL, "https://"):
+		reqURL = "wss://" + strings.TrimPrefix(reqURL, "https://")
+	}
+	req, err := http.NewRequest(GET, reqURL, nil)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, gr.Errors
+	}
+	req = req.WithContext(gr.requestContext())
+	client := initRequest(req, gr)
+	req.Header.Del("Accept-Encoding")
+	if req.Host != "" {
+		req.Header.Set("Host", req.Host)
+	}
+
+	handshake := &WebSocketHandshake{
+		Request:   req,
+		Jar:       client.Jar,
+		Transport: gr.Transport,
+		Timeout:   defaultHandshakeTimeout,
+	}
+	if gr.timeout > 0 {
+		handshake.Timeout = gr.timeout
+	}
+	if gr.deadline > 0 && gr.deadline < handshake.Timeout {
+		handshake.Timeout = gr.deadline
+	}
+	return handshake, nil
+}
@@ -0,0 +1,151 @@
+// Package websocket opens WebSocket connections (RFC 6455) with the settings of a goreq.GoReq,
+// so that gorilla/websocket is only a dependency of programs which import it.
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/smallnest/goreq"
+)
+
+// Message types of WebSocket messages, see RFC 6455.
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+// Dial opens a WebSocket connection to the url of gr, with the opening handshake of gr.WebSocketHandshake.
+// The url can be http, https, ws or wss. The handshake request has the headers, query, cookies and basic auth of gr,
+// and the connection is dialed with its proxy, Socks5, TLS settings and cookie jar. Timeout limits the handshake.
+//
+// For example:
+//    conn, _, errs := websocket.Dial(goreq.New().
+//        Get("wss://example.com/stream").
+//        SetHeader("Authorization", "Bearer token"))
+//    if errs != nil {
+//        return errs
+//    }
+//    defer conn.Close()
+//    conn.KeepAlive(30*time.Second, 10*time.Second)
+//    conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+//    _, msg, err := conn.ReadMessage()
+//
+// The response of the handshake is returned, also if the server refused the upgrade.
+func Dial(gr *goreq.GoReq) (*Conn, goreq.Response, []error) {
+	handshake, errs := gr.WebSocketHandshake()
+	if errs != nil {
+		return nil, nil, errs
+	}
+	dialer := &websocket.Dialer{
+		Jar:              handshake.Jar,
+		HandshakeTimeout: handshake.Timeout,
+	}
+	if transport := handshake.Transport; transport != nil {
+		dialer.Proxy = transport.Proxy
+		dialer.NetDialContext = transport.DialContext
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+
+	ctx, cancel := context.WithTimeout(handshake.Request.Context(), handshake.Timeout)
+	defer cancel()
+	conn, resp, err := dialer.DialContext(ctx, handshake.Request.URL.String(), handshake.Request.Header)
+	if err != nil {
+		gr.Errors = append(gr.Errors, err)
+		return nil, resp, gr.Errors
+	}
+	return &Conn{conn: conn, done: make(chan struct{})}, resp, nil
+}
+
+// Conn is a WebSocket connection opened by Dial.
+// Pings of the server are answered automatically. Messages can be written by many goroutines, but read by one at a time.
+type Conn struct {
+	conn      *websocket.Conn
+	writeMu   sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Conn returns the underlying *websocket.Conn of gorilla/websocket.
+func (c *Conn) Conn() *websocket.Conn {
+	return c.conn
+}
+
+// Subprotocol returns the subprotocol selected by the server.
+func (c *Conn) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
+
+// ReadMessage reads the next text or binary message. It returns a *websocket.CloseError when the server closed the connection.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	return c.conn.ReadMessage()
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v.
+func (c *Conn) ReadJSON(v interface{}) error {
+	return c.conn.ReadJSON(v)
+}
+
+// WriteMessage writes a message of messageType, TextMessage or BinaryMessage.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(messageType, data)
+}
+
+// WriteJSON writes the JSON encoding of v as a text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// Ping sends a ping with data. The pong is handled by the reader of the connection.
+func (c *Conn) Ping(data []byte) error {
+	return c.conn.WriteControl(websocket.PingMessage, data, time.Now().Add(time.Second))
+}
+
+// KeepAlive sends a ping every interval and fails reads with a timeout if no pong arrives within timeout after a ping,
+// so a dead connection is detected. Pongs are only handled while the connection is read.
+func (c *Conn) KeepAlive(interval, timeout time.Duration) {
+	c.conn.SetReadDeadline(time.Now().Add(interval + timeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(interval + timeout))
+	})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// CloseWithCode closes the connection with a close frame with code and reason, see RFC 6455 section 7.4.
+func (c *Conn) CloseWithCode(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		msg := websocket.FormatCloseMessage(code, reason)
+		err = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		if cerr := c.conn.Close(); err == nil || errors.Is(err, websocket.ErrCloseSent) {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// Close closes the connection normally with a close frame.
+func (c *Conn) Close() error {
+	return c.CloseWithCode(websocket.CloseNormalClosure, "")
+}
@@ -0,0 +1,199 @@
+package websocket
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elazarl/goproxy"
+	"github.com/gorilla/websocket"
+	"github.com/smallnest/goreq"
+)
+
+var testUpgrader = websocket.Upgrader{Subprotocols: []string{"chat"}}
+
+// newWebSocketServer starts a server which checks the handshake and echoes messages.
+func newWebSocketServer(t *testing.T, tlsServer bool) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "fookey" {
+			t.Errorf("Expected Header X-Api-Key -> fookey | but got %s", r.Header.Get("X-Api-Key"))
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("Expected basic auth user:pass | but got %s:%s", user, pass)
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s1" {
+			t.Errorf("Expected cookie session=s1 | but got %v", cookie)
+		}
+		if r.URL.Query().Get("room") != "1" {
+			t.Errorf("Expected query room=1 | but got %s", r.URL.RawQuery)
+		}
+		conn, err := testUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					t.Errorf("Expected a normal closure | but got %v", err)
+				}
+				return
+			}
+			conn.WriteMessage(messageType, data)
+		}
+	})
+	if tlsServer {
+		return httptest.NewTLSServer(handler)
+	}
+	return httptest.NewServer(handler)
+}
+
+func newWebSocketRequest(url string) *goreq.GoReq {
+	return goreq.New().
+		Get(url).
+		SetHeader("X-Api-Key", "fookey").
+		SetHeader("Sec-WebSocket-Protocol", "chat").
+		SetBasicAuth("user", "pass").
+		AddCookie(&http.Cookie{Name: "session", Value: "s1"}).
+		Query("room=1")
+}
+
+func echoWebSocket(t *testing.T, gr *goreq.GoReq) {
+	conn, resp, errs := Dial(gr)
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	defer conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols || conn.Subprotocol() != "chat" {
+		t.Errorf("Expected 101 with subprotocol chat | but got %d with %q", resp.StatusCode, conn.Subprotocol())
+	}
+
+	if err := conn.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	messageType, data, err := conn.ReadMessage()
+	if err != nil || messageType != TextMessage || string(data) != "hello" {
+		t.Errorf("Expected echo of hello | but got %d %q %v", messageType, data, err)
+	}
+
+	if err := conn.WriteJSON(map[string]int{"n": 1}); err != nil {
+		t.Fatal(err)
+	}
+	var reply map[string]int
+	if err := conn.ReadJSON(&reply); err != nil || reply["n"] != 1 {
+		t.Errorf("Expected echo of json | but got %v %v", reply, err)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	ts := newWebSocketServer(t, false)
+	defer ts.Close()
+	echoWebSocket(t, newWebSocketRequest(ts.URL))
+}
+
+func TestWebSocketTLSAndProxy(t *testing.T) {
+	ts := newWebSocketServer(t, true)
+	defer ts.Close()
+
+	var connects int32
+	proxy := goproxy.NewProxyHttpServer()
+	proxy.OnRequest().HandleConnectFunc(func(host string, ctx *goproxy.ProxyCtx) (*goproxy.ConnectAction, string) {
+		atomic.AddInt32(&connects, 1)
+		return goproxy.OkConnect, host
+	})
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	echoWebSocket(t, newWebSocketRequest(ts.URL).
+		TLSClientConfig(&tls.Config{InsecureSkipVerify: true}).
+		Proxy(proxyServer.URL))
+	if atomic.LoadInt32(&connects) != 1 {
+		t.Errorf("Expected to connect through the proxy | but got %d connects", connects)
+	}
+
+	// the certificate of the test server is not trusted without the TLS config
+	if _, _, errs := Dial(newWebSocketRequest(ts.URL)); errs == nil {
+		t.Error("Expected an error for an untrusted certificate")
+	}
+}
+
+func TestWebSocketRefused(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	_, resp, errs := Dial(goreq.New().Get(ts.URL))
+	if errs == nil {
+		t.Fatal("Expected an error for a refused handshake")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the 403 response of the handshake | but got %v", resp)
+	}
+}
+
+func TestWebSocketKeepAlive(t *testing.T) {
+	var pings int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := testUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		answer := r.URL.Path == "/pong"
+		conn.SetPingHandler(func(data string) error {
+			atomic.AddInt32(&pings, 1)
+			if answer {
+				return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+			}
+			return nil
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+
+	// the server answers pings, so the connection stays alive
+	conn, _, errs := Dial(goreq.New().Get(ts.URL + "/pong"))
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	conn.KeepAlive(20*time.Millisecond, 100*time.Millisecond)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		conn.Close()
+	}()
+	_, _, err := conn.ReadMessage()
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		t.Errorf("Expected the connection to stay alive | but got %v", err)
+	}
+	if atomic.LoadInt32(&pings) < 3 {
+		t.Errorf("Expected pings every 20ms | but got %d", pings)
+	}
+
+	// the server doesn't answer pings, so reading times out
+	conn, _, errs = Dial(goreq.New().Get(ts.URL + "/silent"))
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	defer conn.Close()
+	conn.KeepAlive(20*time.Millisecond, 50*time.Millisecond)
+	start := time.Now()
+	_, _, err = conn.ReadMessage()
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Errorf("Expected a timeout | but got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected a timeout after 70ms | but took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,37 @@
+package goreq
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebSocketHandshake(t *testing.T) {
+	handshake, errs := New().
+		Get("https://example.com/chat").
+		SetHeader("X-Api-Key", "fookey").
+		SetBasicAuth("user", "pass").
+		Query("room=1").
+		Timeout(10 * time.Second).
+		Deadline(time.Second).
+		WebSocketHandshake()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	req := handshake.Request
+	if req.URL.String() != "wss://example.com/chat?room=1" {
+		t.Errorf("Expected url wss://example.com/chat?room=1 | but got %s", req.URL)
+	}
+	if req.Header.Get("X-Api-Key") != "fookey" || req.Header.Get("Accept-Encoding") != "" {
+		t.Errorf("Expected Header X-Api-Key without Accept-Encoding | but got %v", req.Header)
+	}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "user" || pass != "pass" {
+		t.Errorf("Expected basic auth user:pass | but got %s:%s", user, pass)
+	}
+	if handshake.Timeout != time.Second {
+		t.Errorf("Expected the deadline as timeout | but got %s", handshake.Timeout)
+	}
+
+	if handshake, _ = New().Get("http://example.com").WebSocketHandshake(); handshake.Timeout != defaultHandshakeTimeout {
+		t.Errorf("Expected the default timeout | but got %s", handshake.Timeout)
+	}
+}
@@ -0,0 +1,182 @@
+package goreq
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+func init() {
+	RegisterCodec(&XMLCodec{})
+}
+
+// XMLCodec encodes and decodes XML with encoding/xml. It is registered for application/xml and text/xml,
+// and used for media types with the +xml suffix such as application/atom+xml.
+//
+// Namespaces are handled by encoding/xml: a value is encoded and decoded in a namespace by its XMLName field
+// or tags like `xml:"http://www.w3.org/2005/Atom feed"`, and fields without a namespace match elements in any namespace.
+// Register another XMLCodec to change the options:
+//
+//    goreq.RegisterCodec(&goreq.XMLCodec{Header: true, DefaultSpace: "http://www.w3.org/2005/Atom"})
+//
+type XMLCodec struct {
+	// Header writes xml.Header before encoded values.
+	Header bool
+	// Indent indents encoded elements if it is not empty.
+	Indent string
+	// DefaultSpace is the namespace of decoded elements which are not in a namespace.
+	DefaultSpace string
+}
+
+// MediaTypes returns application/xml and text/xml.
+func (c *XMLCodec) MediaTypes() []string {
+	return []string{"application/xml", "text/xml"}
+}
+
+// Encode writes the XML encoding of v to w.
+func (c *XMLCodec) Encode(w io.Writer, v interface{}) error {
+	if c.Header {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", c.Indent)
+	return enc.Encode(v)
+}
+
+// Decode decodes XML from r into v. Documents in other encodings than UTF-8 are converted by their encoding declaration.
+func (c *XMLCodec) Decode(r io.Reader, v interface{}) error {
+	d := xml.NewDecoder(r)
+	d.DefaultSpace = c.DefaultSpace
+	d.CharsetReader = charset.NewReaderLabel
+	return d.Decode(v)
+}
+
+// SendXML sends the XML encoding of v as the body, with the Content-Type application/xml unless another one is set:
+//      type Person struct {
+//        XMLName xml.Name `xml:"urn:example:people person"`
+//        Name    string   `xml:"name"`
+//      }
+//      goreq.New().
+//        Post("/people").
+//        SendXML(Person{Name: "nemo"}).
+//        End()
+//
+// Like SendJSON, SendXML replaces a previous value and can not be combined with SendStruct or SendMapString.
+func (gr *GoReq) SendXML(v interface{}) *GoReq {
+	gr.bodyValue = &bodyValue{value: v, codec: LookupCodec("application/xml")}
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/xml"
+	}
+	return gr
+}
+
+// SetPrettyXML enables indenting XML request and response bodies in the logs of the debug mode.
+func (gr *GoReq) SetPrettyXML(enable bool) *GoReq {
+	gr.prettyXML = enable
+	return gr
+}
+
+// isXML reports whether a Content-Type is XML.
+func isXML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// prettyDump indents the XML body of a dumped request or response if SetPrettyXML is enabled.
+func (gr *GoReq) prettyDump(dump []byte, contentType string) []byte {
+	if !gr.prettyXML || !isXML(contentType) {
+		return dump
+	}
+	i := bytes.Index(dump, []byte("\r\n\r\n"))
+	if i < 0 {
+		return dump
+	}
+	body := indentXML(dump[i+4:], "  ")
+	return append(dump[:i+4:i+4], body...)
+}
+
+// indentXML returns data with one element per line, indented by indent.
+// It returns data if data is not well formed XML.
+func indentXML(data []byte, indent string) []byte {
+	var (
+		buf   bytes.Buffer
+		depth int
+		prev  xml.Token
+	)
+	newline := func() {
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(strings.Repeat(indent, depth))
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return data
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			newline()
+			buf.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				buf.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				xml.EscapeText(&buf, []byte(attr.Value))
+				buf.WriteByte('"')
+			}
+			buf.WriteByte('>')
+			depth++
+		case xml.EndElement:
+			depth--
+			// elements with only text stay on one line
+			switch prev.(type) {
+			case xml.StartElement, xml.CharData:
+			default:
+				newline()
+			}
+			buf.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			text := bytes.TrimSpace(t)
+			if len(text) == 0 {
+				continue
+			}
+			xml.EscapeText(&buf, text)
+			token = xml.CharData(text)
+		case xml.Comment:
+			newline()
+			buf.WriteString("<!--" + string(t) + "-->")
+		case xml.ProcInst:
+			newline()
+			buf.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+		case xml.Directive:
+			newline()
+			buf.WriteString("<!" + string(t) + ">")
+		}
+		prev = xml.CopyToken(token)
+	}
+	if depth != 0 {
+		return data
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
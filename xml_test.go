@@ -0,0 +1,175 @@
+package goreq
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+}
+
+func TestSendXML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/xml" {
+			t.Errorf("Expected Header Content-Type -> application/xml | but got %s", r.Header.Get("Content-Type"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `<feed xmlns="http://www.w3.org/2005/Atom"><title>goreq</title><entry><id>1</id><title>first</title></entry></feed>`
+		if string(body) != expected {
+			t.Errorf("Expected Body %s | but got %s", expected, body)
+		}
+	}))
+	defer ts.Close()
+
+	feed := atomFeed{Title: "goreq", Entries: []atomEntry{{"1", "first"}}}
+	_, _, errs := New().Post(ts.URL).SendXML(feed).End()
+	if errs != nil {
+		t.Errorf("Unexpected errors: %s", errs)
+	}
+
+	_, _, errs = New().Post(ts.URL).SendMapString(`{"title":"goreq"}`).SendXML(feed).End()
+	if errs == nil {
+		t.Error("Expected an error for SendXML with SendMapString")
+	}
+}
+
+func TestSendXMLContentType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write([]byte(r.Header.Get("Content-Type") + " " + string(body)))
+	}))
+	defer ts.Close()
+
+	type person struct {
+		XMLName xml.Name `xml:"person" json:"-"`
+		Name    string   `xml:"name" json:"name"`
+	}
+	cases := []struct {
+		req      *GoReq
+		expected string
+	}{
+		// the struct itself is encoded as XML
+		{New().Post(ts.URL).ContentType("xml").SendStruct(person{Name: "nemo"}), "application/xml <person><name>nemo</name></person>"},
+		// the codec of the Content-Type set after SendXML is used
+		{New().Post(ts.URL).SendXML(person{Name: "nemo"}).ContentType("json"), `application/json {"name":"nemo"}`},
+		{New().Post(ts.URL).SendJSON(person{Name: "nemo"}).ContentType("xml"), "application/xml <person><name>nemo</name></person>"},
+	}
+	for _, c := range cases {
+		_, body, errs := c.req.End()
+		if errs != nil {
+			t.Errorf("Unexpected errors: %s", errs)
+		}
+		if body != c.expected {
+			t.Errorf("Expected %s | but got %s", c.expected, body)
+		}
+	}
+
+	// merged data can not be encoded as XML
+	_, _, errs := New().Post(ts.URL).ContentType("xml").SendStruct(person{Name: "nemo"}).SendMapString(`{"age":3}`).End()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "can not encode the body as application/xml") {
+		t.Errorf("Expected an error for merged data as XML | but got %v", errs)
+	}
+}
+
+func TestBindBodyXML(t *testing.T) {
+	const serverOutput = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<a:feed xmlns:a="http://www.w3.org/2005/Atom">
+  <a:title>caf` + "\xe9" + `</a:title>
+  <a:entry><a:id>1</a:id><a:title>first</a:title></a:entry>
+  <a:entry><a:id>2</a:id><a:title>second</a:title></a:entry>
+</a:feed>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.Write([]byte(serverOutput))
+	}))
+	defer ts.Close()
+
+	var feed atomFeed
+	_, _, errs := New().Get(ts.URL).BindBody(&feed).End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if feed.Title != "café" || len(feed.Entries) != 2 || feed.Entries[1].Title != "second" {
+		t.Errorf("Expected BindBody to decode the feed | but got %+v", feed)
+	}
+}
+
+func TestXMLCodecDefaultSpace(t *testing.T) {
+	var feed atomFeed
+	codec := &XMLCodec{DefaultSpace: "http://www.w3.org/2005/Atom"}
+	if err := codec.Decode(strings.NewReader(`<feed><title>goreq</title></feed>`), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "goreq" {
+		t.Errorf("Expected title goreq | but got %s", feed.Title)
+	}
+
+	if err := (&XMLCodec{}).Decode(strings.NewReader(`<feed xmlns="urn:other"><title>goreq</title></feed>`), &feed); err == nil {
+		t.Error("Expected an error for a feed in another namespace")
+	}
+
+	var buf bytes.Buffer
+	codec = &XMLCodec{Header: true, Indent: "  "}
+	if err := codec.Encode(&buf, atomEntry{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := xml.Header + "<atomEntry>\n  <id>1</id>\n  <title></title>\n</atomEntry>"
+	if buf.String() != expected {
+		t.Errorf("Expected %q | but got %q", expected, buf.String())
+	}
+}
+
+func TestIndentXML(t *testing.T) {
+	data := `<?xml version="1.0"?><!-- feed --><a:feed xmlns:a="urn:a" id="1"><a:title>x &amp; y</a:title><a:empty></a:empty><a:entry><a:id>1</a:id></a:entry></a:feed>`
+	expected := `<?xml version="1.0"?>
+<!-- feed -->
+<a:feed xmlns:a="urn:a" id="1">
+  <a:title>x &amp; y</a:title>
+  <a:empty></a:empty>
+  <a:entry>
+    <a:id>1</a:id>
+  </a:entry>
+</a:feed>
+`
+	if indented := string(indentXML([]byte(data), "  ")); indented != expected {
+		t.Errorf("Expected %s | but got %s", expected, indented)
+	}
+	if indented := string(indentXML([]byte("<a><b></a>"), "  ")); indented != "<a><b></a>" {
+		t.Errorf("Expected malformed XML unchanged | but got %s", indented)
+	}
+}
+
+func TestPrettyXML(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<feed><title>goreq</title></feed>`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	_, _, errs := New().Get(ts.URL).
+		SetDebug(true).
+		SetPrettyXML(true).
+		SetLogger(log.New(&buf, "", 0)).
+		End()
+	if errs != nil {
+		t.Fatalf("Unexpected errors: %s", errs)
+	}
+	if !strings.Contains(buf.String(), "<feed>\n  <title>goreq</title>\n</feed>") {
+		t.Errorf("Expected an indented response body in the debug log | but got %s", buf.String())
+	}
+}
@@ -0,0 +1,23 @@
+package goreq
+
+// SendYAML sends the YAML encoding of v as the body, with the Content-Type application/yaml unless another one is set.
+// The YAML codec is registered by importing github.com/smallnest/goreq/codec/yaml:
+//      import _ "github.com/smallnest/goreq/codec/yaml"
+//
+//      type Config struct {
+//        Name     string `yaml:"name"`
+//        Replicas int    `yaml:"replicas"`
+//      }
+//      goreq.New().
+//        Put("/configs/web").
+//        SendYAML(Config{Name: "web", Replicas: 3}).
+//        End()
+//
+// Like SendJSON, SendYAML replaces a previous value and can not be combined with SendStruct or SendMapString.
+func (gr *GoReq) SendYAML(v interface{}) *GoReq {
+	if gr.Header["Content-Type"] == "" {
+		gr.Header["Content-Type"] = "application/yaml"
+	}
+	gr.bodyValue = &bodyValue{value: v}
+	return gr
+}